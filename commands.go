@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/meilke/structure/orgstruct"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+// commands maps the name of a subcommand to its implementation. Without a
+// subcommand the exports are validated.
+var commands = map[string]func(args []string){
+	"diff":   runDiff,
+	"export": runExport,
+	"query":  runQuery,
+	"tree":   runTree,
+}
+
+// selectAt picks the versions valid at the given date (YYYY-MM-DD) unless it
+// is empty.
+func selectAt(m *orgstruct.Model, at string) error {
+	if at == "" {
+		return nil
+	}
+
+	date, err := time.Parse("2006-01-02", at)
+	if err != nil {
+		return err
+	}
+	m.SelectAt(date)
+	return nil
+}
+
+// loadModel loads the exports given by path.
+func loadModel(fsPath string, kuPath string, oePath string, at string) (*orgstruct.Model, error) {
+	m, err := orgstruct.Load(fsPath, kuPath, oePath)
+	if err != nil {
+		return nil, err
+	}
+	return m, selectAt(m, at)
+}
+
+// loadModelDir loads XML_FS.xml, XML_KU.xml and XML_OE.xml from a directory.
+func loadModelDir(dir string, at string) (*orgstruct.Model, error) {
+	m, err := orgstruct.LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return m, selectAt(m, at)
+}
+
+// analysisFlags holds the flags tuning the analysis, shared by the
+// validation and the subcommands analyzing the exports.
+type analysisFlags struct {
+	normalizeNames *string
+	severities     *string
+	config         *string
+	mixedCycles    *bool
+}
+
+func addAnalysisFlags(flags *flag.FlagSet) *analysisFlags {
+	return &analysisFlags{
+		normalizeNames: flags.String("normalize-names", "whitespace,case,entities", "differences ignored when comparing OE names with KU and FS names"),
+		severities:     flags.String("severity", "", "severity overrides, e.g. NameMismatch=info,VersionGap=error"),
+		config:         flags.String("config", "", "path to a JSON rule configuration file"),
+		mixedCycles:    flags.Bool("mixed-cycles", false, "also check the combined L and F hierarchy of OE items for cycles"),
+	}
+}
+
+// options returns the default options tuned by the flags.
+func (a *analysisFlags) options() orgstruct.Options {
+	options := orgstruct.DefaultOptions()
+	options.MixedOECycles = *a.mixedCycles
+
+	var err error
+	options.Names, err = orgstruct.ParseNameNormalization(*a.normalizeNames)
+	exitOnError(err)
+	options.Severities, err = orgstruct.ParseSeverities(*a.severities)
+	exitOnError(err)
+	if *a.config != "" {
+		options.Rules, err = orgstruct.LoadConfig(*a.config)
+		exitOnError(err)
+	}
+	return options
+}
+
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	oldDir := flags.String("old", "", "directory with the old XML_FS.xml, XML_KU.xml and XML_OE.xml files")
+	newDir := flags.String("new", "", "directory with the new XML_FS.xml, XML_KU.xml and XML_OE.xml files")
+	format := flags.String("format", "text", "output format (text or json)")
+	at := flags.String("at", "", "compare the items valid at this date (YYYY-MM-DD) instead of the latest versions")
+	findings := flags.Bool("findings", false, "compare the findings of both snapshots instead of their items")
+	analysis := addAnalysisFlags(flags)
+	failOn := flags.String("fail-on", "", "with -findings, exit with status 2 if there are new findings of at least this severity (info, warning or error)")
+	flags.Parse(args)
+
+	if *oldDir == "" || *newDir == "" {
+		exitOnError(fmt.Errorf("both -old and -new are required"))
+	}
+	if *format != "text" && *format != "json" {
+		exitOnError(fmt.Errorf("unknown output format: %s", *format))
+	}
+
+	options := analysis.options()
+
+	var threshold orgstruct.Severity
+	if *failOn != "" {
+		var err error
+		threshold, err = orgstruct.ParseSeverity(*failOn)
+		exitOnError(err)
+	}
+
+	log.SetOutput(os.Stderr)
+
+	log.WithFields(log.Fields{
+		"path": *oldDir,
+	}).Info("loading old snapshot...")
+	old, err := loadModelDir(*oldDir, *at)
+	exitOnError(err)
+
+	log.WithFields(log.Fields{
+		"path": *newDir,
+	}).Info("loading new snapshot...")
+	next, err := loadModelDir(*newDir, *at)
+	exitOnError(err)
+
+	if *findings {
+		d := orgstruct.DiffFindings(old.Analyze(options), next.Analyze(options))
+		if *format == "json" {
+			exitOnError(orgstruct.WriteJSONFindingsDiff(os.Stdout, d))
+		} else {
+			exitOnError(orgstruct.WriteTextFindingsDiff(os.Stdout, d))
+		}
+
+		if *failOn != "" && d.Fails(threshold) {
+			os.Exit(2)
+		}
+		return
+	}
+
+	d := orgstruct.DiffModels(old, next)
+	if *format == "json" {
+		exitOnError(orgstruct.WriteJSONDiff(os.Stdout, d))
+	} else {
+		exitOnError(orgstruct.WriteTextDiff(os.Stdout, d))
+	}
+}
+
+func runTree(args []string) {
+	flags := flag.NewFlagSet("tree", flag.ExitOnError)
+	fsPath := flags.String("fs", "XML_FS.xml", "path to XML_FS.xml file")
+	kuPath := flags.String("ku", "XML_KU.xml", "path to XML_KU.xml file")
+	oePath := flags.String("oe", "XML_OE.xml", "path to XML_OE.xml file")
+	at := flags.String("at", "", "render the items valid at this date (YYYY-MM-DD) instead of the latest versions")
+	hierarchy := flags.String("hierarchy", orgstruct.TreeOEL, "hierarchy to render (ku, fs, oe-l or oe-f)")
+	root := flags.String("root", "", "id of the node to start at instead of all roots")
+	depth := flags.Int("depth", 0, "maximum number of levels below the start nodes (0 for no limit)")
+	errors := flags.Bool("errors", true, "annotate items with the messages of their errors")
+	ascii := flags.Bool("ascii", false, "draw branches with ASCII instead of box-drawing characters")
+	analysis := addAnalysisFlags(flags)
+	flags.Parse(args)
+
+	log.SetOutput(os.Stderr)
+
+	options := analysis.options()
+
+	m, err := loadModel(*fsPath, *kuPath, *oePath, *at)
+	exitOnError(err)
+	m.Analyze(options)
+
+	treeOptions := orgstruct.TreeOptions{Root: *root, MaxDepth: *depth, Errors: *errors, ASCII: *ascii}
+	exitOnError(orgstruct.RenderTree(os.Stdout, *hierarchy, treeOptions, m.OE, m.KU, m.FS))
+}
+
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	fsPath := flags.String("fs", "XML_FS.xml", "path to XML_FS.xml file")
+	kuPath := flags.String("ku", "XML_KU.xml", "path to XML_KU.xml file")
+	oePath := flags.String("oe", "XML_OE.xml", "path to XML_OE.xml file")
+	at := flags.String("at", "", "export the items valid at this date (YYYY-MM-DD) instead of the latest versions")
+	format := flags.String("format", "dot", "output format (dot)")
+	hierarchy := flags.String("hierarchy", orgstruct.TreeOEL, "hierarchy the root is looked up in (ku, fs, oe-l or oe-f)")
+	root := flags.String("root", "", "id of the item whose subtree is exported instead of everything")
+	analysis := addAnalysisFlags(flags)
+	flags.Parse(args)
+
+	if *format != "dot" {
+		exitOnError(fmt.Errorf("unknown output format: %s", *format))
+	}
+
+	log.SetOutput(os.Stderr)
+
+	options := analysis.options()
+
+	m, err := loadModel(*fsPath, *kuPath, *oePath, *at)
+	exitOnError(err)
+	result := m.Analyze(options)
+
+	exportOptions := orgstruct.ExportOptions{Root: *root, Hierarchy: *hierarchy}
+	exitOnError(orgstruct.WriteDOT(os.Stdout, exportOptions, m.OE, m.KU, m.FS, result))
+}
+
+func runQuery(args []string) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	fsPath := flags.String("fs", "XML_FS.xml", "path to XML_FS.xml file")
+	kuPath := flags.String("ku", "XML_KU.xml", "path to XML_KU.xml file")
+	oePath := flags.String("oe", "XML_OE.xml", "path to XML_OE.xml file")
+	at := flags.String("at", "", "query the items valid at this date (YYYY-MM-DD) instead of the latest versions")
+	format := flags.String("format", "table", "output format (table or json)")
+	analysis := addAnalysisFlags(flags)
+	orgKZ := flags.String("orgkz", "", "Org-Kz of the OE, a trailing * matches a prefix")
+	name := flags.String("name", "", "part of Org-Bez1, Org-Bez2 or Org-Bez3")
+	location := flags.String("standort", "", "Standort of the OE")
+	typ := flags.String("typ", "", "Typ of the OE")
+	kuId := flags.String("kuid", "", "id of the KU of the OE")
+	fsId := flags.String("fsid", "", "id of the FS of the OE")
+	flags.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		exitOnError(fmt.Errorf("unknown output format: %s", *format))
+	}
+
+	log.SetOutput(os.Stderr)
+
+	options := analysis.options()
+	m, err := loadModel(*fsPath, *kuPath, *oePath, *at)
+	exitOnError(err)
+	m.Analyze(options)
+
+	q := orgstruct.Query{OrgKZ: *orgKZ, Name: *name, Location: *location, Type: *typ, KUId: *kuId, FSId: *fsId}
+	results := m.RunQuery(q)
+	if *format == "json" {
+		exitOnError(orgstruct.WriteJSONQuery(os.Stdout, results))
+	} else {
+		exitOnError(orgstruct.WriteQueryTable(os.Stdout, results))
+	}
+}
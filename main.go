@@ -2,8 +2,11 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"github.com/meilke/structure/orgstruct"
 	log "github.com/sirupsen/logrus"
 	"os"
+	"time"
 )
 
 func exitOnError(err error) {
@@ -26,97 +29,158 @@ var logLevels = map[string]log.Level{
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
+
 	fsPath := flag.String("fs", "XML_FS.xml", "path to XML_FS.xml file")
 	kuPath := flag.String("ku", "XML_KU.xml", "path to XML_KU.xml file")
 	oePath := flag.String("oe", "XML_OE.xml", "path to XML_OE.xml file")
 	logLevel := flag.String("log", "info", "log level")
+	format := flag.String("format", "text", "output format (text, json or html)")
+	at := flag.String("at", "", "only analyze items valid at this date (YYYY-MM-DD)")
+	failOn := flag.String("fail-on", "", "exit with status 2 if there are findings of at least this severity (info, warning or error)")
+	baseline := flag.String("baseline", "", "path to a baseline file with accepted findings that are not reported")
+	writeBaselinePath := flag.String("write-baseline", "", "write all current findings to this baseline file")
+	analysis := addAnalysisFlags(flag.CommandLine)
 	flag.Parse()
 
-	log.SetOutput(os.Stdout)
+	if *format != "text" && *format != "json" && *format != "html" {
+		exitOnError(fmt.Errorf("unknown output format: %s", *format))
+	}
+
+	if *format != "text" {
+		log.SetOutput(os.Stderr)
+	} else {
+		log.SetOutput(os.Stdout)
+	}
 	log.SetLevel(logLevels[*logLevel])
 
-	log.WithFields(log.Fields{
-		"path": *fsPath,
-	}).Info("parsing FS data...")
-	fsMap, err := parseFS(*fsPath)
-	exitOnError(err)
-	log.Info("successfully parsed FS data!")
+	options := analysis.options()
 
-	log.WithFields(log.Fields{
-		"path": *kuPath,
-	}).Info("parsing KU data...")
-	kuMap, err := parseKU(*kuPath)
-	exitOnError(err)
-	log.Info("successfully parsed KU data!")
+	var threshold orgstruct.Severity
+	if *failOn != "" {
+		var err error
+		threshold, err = orgstruct.ParseSeverity(*failOn)
+		exitOnError(err)
+	}
 
 	log.WithFields(log.Fields{
-		"path": *oePath,
-	}).Info("parsing OE data...")
-	oeItems, oeMap, err := parseOE(*oePath)
+		"FS": *fsPath,
+		"KU": *kuPath,
+		"OE": *oePath,
+	}).Info("parsing data...")
+	m, err := orgstruct.Load(*fsPath, *kuPath, *oePath)
 	exitOnError(err)
-	log.Info("successfully parsed OE data!")
+	log.WithFields(log.Fields{
+		"OE":         len(m.OE),
+		"KU":         len(m.KU),
+		"FS":         len(m.FS),
+		"duplicates": len(m.OEDuplicates) + len(m.KUDuplicates) + len(m.FSDuplicates),
+	}).Debug("parsed items")
+	log.Info("successfully parsed data!")
+
+	if *at != "" {
+		date, err := time.Parse("2006-01-02", *at)
+		exitOnError(err)
+		m.SelectAt(date)
+		log.WithFields(log.Fields{
+			"at": *at,
+			"OE": len(m.OE),
+			"KU": len(m.KU),
+			"FS": len(m.FS),
+		}).Info("selected items valid at date")
+	}
 
 	log.Info("building trees...")
-	buildTrees(oeMap, kuMap, fsMap)
+	m.Build()
 	log.Info("successfully built trees!")
 	log.Info("analyzing trees...")
-	analyzeTrees(oeMap, kuMap, fsMap)
+	result := m.Analyze(options)
 	log.Info("successfully analyzed trees!")
 
-	var foundError bool
-
-	foundError = false
-	for _, item := range oeItems {
-		if len(item.Errors) > 0 {
-			foundError = true
-			for _, e := range item.Errors {
-				log.WithFields(log.Fields{
-					"id":      item.Id,
-					"name":    item.OrgKZ,
-					"message": e.Message,
-				}).Info("OE with errors")
-			}
-		}
+	if *writeBaselinePath != "" {
+		exitOnError(orgstruct.SaveBaseline(*writeBaselinePath, result))
+		log.WithFields(log.Fields{
+			"path": *writeBaselinePath,
+		}).Info("wrote baseline")
 	}
 
-	if !foundError {
-		log.Info("did not find any errors in OE data!")
+	if *baseline != "" {
+		b, err := orgstruct.LoadBaseline(*baseline)
+		exitOnError(err)
+		result.ApplyBaseline(b)
+		log.WithFields(log.Fields{
+			"path":     *baseline,
+			"accepted": len(b.Entries),
+			"fixed":    len(result.Fixed),
+		}).Info("applied baseline")
 	}
 
-	foundError = false
-	for _, item := range kuMap {
-		if len(item.Errors) > 0 {
-			foundError = true
-			for _, e := range item.Errors {
-				log.WithFields(log.Fields{
-					"id":      item.Id,
-					"name":    item.NameLong,
-					"message": e.Message,
-				}).Info("KU with errors")
-			}
-		}
+	switch *format {
+	case "json":
+		exitOnError(orgstruct.WriteJSONReport(os.Stdout, result))
+	case "html":
+		exitOnError(orgstruct.WriteHTMLReport(os.Stdout, m.OE, result))
+	default:
+		logFindings(result)
+		logFixed(result)
 	}
 
-	if !foundError {
-		log.Info("did not find any errors in KU data!")
+	counts := result.CountBySeverity()
+	log.WithFields(log.Fields{
+		"error":   counts[orgstruct.SeverityError],
+		"warning": counts[orgstruct.SeverityWarning],
+		"info":    counts[orgstruct.SeverityInfo],
+	}).Info("summary")
+
+	if *failOn != "" && result.Fails(threshold) {
+		os.Exit(2)
 	}
+}
 
-	foundError = false
-	for _, item := range fsMap {
-		if len(item.Errors) > 0 {
-			foundError = true
-			for _, e := range item.Errors {
-				log.WithFields(log.Fields{
-					"id":      item.Id,
-					"name":    item.NameLong,
-					"message": e.Message,
-				}).Info("FS with errors")
+func logFindings(result *orgstruct.Errors) {
+	for _, kind := range []string{orgstruct.KindOE, orgstruct.KindKU, orgstruct.KindFS} {
+		findings := result.Filter(func(f *orgstruct.Finding) bool {
+			return f.Kind == kind
+		})
+
+		for _, f := range findings {
+			fields := log.Fields{
+				"id":      f.Id,
+				"name":    f.Name,
+				"message": f.Message,
+			}
+			if f.Detail != "" {
+				fields["detail"] = f.Detail
+			}
+
+			entry := log.WithFields(fields)
+			switch f.Severity {
+			case orgstruct.SeverityError:
+				entry.Error(kind + " with errors")
+			case orgstruct.SeverityWarning:
+				entry.Warn(kind + " with errors")
+			default:
+				entry.Info(kind + " with errors")
 			}
 		}
-	}
 
-	if !foundError {
-		log.Info("did not find any errors in FS data!")
+		if len(findings) == 0 {
+			log.Info("did not find any errors in " + kind + " data!")
+		}
 	}
+}
 
+func logFixed(result *orgstruct.Errors) {
+	for _, entry := range result.Fixed {
+		log.WithFields(log.Fields{
+			"id":   entry.Id,
+			"name": entry.Name,
+			"type": entry.Type,
+		}).Info(entry.Kind + " baseline finding fixed")
+	}
 }
@@ -0,0 +1,386 @@
+package orgstruct
+
+import (
+	"sort"
+)
+
+// OEError is an error reported on an OE item.
+type OEError struct {
+	*Error
+	OE *OEItem
+}
+
+// KUError is an error reported on a KU item.
+type KUError struct {
+	*Error
+	KU *KUItem
+}
+
+// FSError is an error reported on an FS item.
+type FSError struct {
+	*Error
+	FS *FSItem
+}
+
+const (
+	KindOE = "OE"
+	KindKU = "KU"
+	KindFS = "FS"
+)
+
+// Errors is the result of an analysis: the errors by kind of item and the
+// cycles found.
+type Errors struct {
+	OEErrors []*OEError
+	KUErrors []*KUError
+	FSErrors []*FSError
+	Cycles   []*Cycle
+	// Fixed lists the entries of the baseline applied with ApplyBaseline
+	// that no longer have a matching error.
+	Fixed []*BaselineEntry
+
+	cycles     map[string]*Cycle
+	severities map[ErrorType]Severity
+	rules      map[string]*Rule
+}
+
+func newErrors(options Options) *Errors {
+	return &Errors{severities: options.Severities, rules: options.Rules}
+}
+
+// severity returns the severity of an error, taking overrides into account.
+func (errors *Errors) severity(e *Error) Severity {
+	if s, ok := errors.severities[e.Type]; ok {
+		return s
+	}
+	for _, rule := range errors.rulesFor(e) {
+		if rule.Severity != nil {
+			return *rule.Severity
+		}
+	}
+	return defaultSeverity(e.Type)
+}
+
+// Finding is a single error together with the item it was found on,
+// independent of the item's kind.
+type Finding struct {
+	*Error
+	Kind string
+	Id   string
+	Name string
+	Line int
+}
+
+func lessError(idA string, a *Error, idB string, b *Error) bool {
+	if idA != idB {
+		return idA < idB
+	}
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Message != b.Message {
+		return a.Message < b.Message
+	}
+	if a.Reference != b.Reference {
+		return a.Reference < b.Reference
+	}
+	return a.Detail < b.Detail
+}
+
+// sort orders the errors of each kind by item id, then error type, and the
+// cycles by kind, hierarchy and first id.
+func (errors *Errors) sort() {
+	sort.SliceStable(errors.OEErrors, func(i, j int) bool {
+		a, b := errors.OEErrors[i], errors.OEErrors[j]
+		return lessError(a.OE.Id, a.Error, b.OE.Id, b.Error)
+	})
+	sort.SliceStable(errors.KUErrors, func(i, j int) bool {
+		a, b := errors.KUErrors[i], errors.KUErrors[j]
+		return lessError(a.KU.Id, a.Error, b.KU.Id, b.Error)
+	})
+	sort.SliceStable(errors.FSErrors, func(i, j int) bool {
+		a, b := errors.FSErrors[i], errors.FSErrors[j]
+		return lessError(a.FS.Id, a.Error, b.FS.Id, b.Error)
+	})
+	sort.SliceStable(errors.Cycles, func(i, j int) bool {
+		a, b := errors.Cycles[i], errors.Cycles[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Hierarchy != b.Hierarchy {
+			return a.Hierarchy < b.Hierarchy
+		}
+		return a.Ids[0] < b.Ids[0]
+	})
+}
+
+// Findings returns all errors ordered by kind, then item id, then error type.
+func (errors *Errors) Findings() []*Finding {
+	findings := make([]*Finding, 0, errors.Count())
+
+	for _, e := range errors.FSErrors {
+		findings = append(findings, &Finding{Error: e.Error, Kind: KindFS, Id: e.FS.Id, Name: e.FS.NameLong, Line: e.FS.Line})
+	}
+
+	for _, e := range errors.KUErrors {
+		findings = append(findings, &Finding{Error: e.Error, Kind: KindKU, Id: e.KU.Id, Name: e.KU.NameLong, Line: e.KU.Line})
+	}
+
+	for _, e := range errors.OEErrors {
+		findings = append(findings, &Finding{Error: e.Error, Kind: KindOE, Id: e.OE.Id, Name: e.OE.OrgKZ, Line: e.OE.Line})
+	}
+
+	return findings
+}
+
+// Filter returns the findings for which keep returns true, in the order of
+// Findings.
+func (errors *Errors) Filter(keep func(*Finding) bool) []*Finding {
+	var findings []*Finding
+	for _, f := range errors.Findings() {
+		if keep(f) {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Count returns the total number of errors of all kinds.
+func (errors *Errors) Count() int {
+	return len(errors.OEErrors) + len(errors.KUErrors) + len(errors.FSErrors)
+}
+
+func buildTrees(oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) {
+	for _, v := range kuMap {
+		if v.ParentId != "" {
+			v.Parent = kuMap[v.ParentId]
+			if v.Parent != nil {
+				v.Parent.Children = append(v.Parent.Children, v)
+			}
+		}
+	}
+
+	for _, v := range fsMap {
+		if v.ParentId != "" {
+			v.Parent = fsMap[v.ParentId]
+			if v.Parent != nil {
+				v.Parent.Children = append(v.Parent.Children, v)
+			}
+		}
+	}
+
+	for _, v := range oeMap {
+		if v.ParentLId != "" {
+			v.ParentL = oeMap[v.ParentLId]
+			if v.ParentL != nil {
+				v.ParentL.LChildren = append(v.ParentL.LChildren, v)
+			}
+		}
+
+		if v.ParentFId != "" {
+			v.ParentF = oeMap[v.ParentFId]
+			if v.ParentF != nil {
+				v.ParentF.FChildren = append(v.ParentF.FChildren, v)
+			}
+		}
+
+		if v.KUId != "" {
+			v.KU = kuMap[v.KUId]
+			if v.KU != nil {
+				v.KU.OE = append(v.KU.OE, v)
+			}
+		}
+
+		if v.FSId != "" {
+			v.FS = fsMap[v.FSId]
+			if v.FS != nil {
+				v.FS.OE = append(v.FS.OE, v)
+			}
+		}
+	}
+}
+
+func addMissingReferenceError(item *ItemWithError, message string) *Error {
+	e := &Error{Message: message, Type: MissingReference}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addNonExistingReferenceError(item *ItemWithError, message string, reference string) *Error {
+	e := &Error{Message: message, Type: NonExistingReference, Reference: reference}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addInvalidValidityError(item *ItemWithError, message string) *Error {
+	e := &Error{Message: message, Type: InvalidValidity}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addValidityNotContainedError(item *ItemWithError, message string, reference string) *Error {
+	e := &Error{Message: message, Type: ValidityNotContained, Reference: reference}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addValidityNotCoveredError(item *ItemWithError, message string, reference string) *Error {
+	e := &Error{Message: message, Type: ValidityNotCovered, Reference: reference}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addKUError(item *KUItem, e *Error, errors *Errors) bool {
+	if !errors.applies(e, kuSubject(item)) {
+		item.removeError(e)
+		return false
+	}
+	e.Severity = errors.severity(e)
+	errors.KUErrors = append(errors.KUErrors, &KUError{KU: item, Error: e})
+	return true
+}
+
+func addFSError(item *FSItem, e *Error, errors *Errors) bool {
+	if !errors.applies(e, fsSubject(item)) {
+		item.removeError(e)
+		return false
+	}
+	e.Severity = errors.severity(e)
+	errors.FSErrors = append(errors.FSErrors, &FSError{FS: item, Error: e})
+	return true
+}
+
+func addOEError(item *OEItem, e *Error, errors *Errors) bool {
+	if !errors.applies(e, oeSubject(item)) {
+		item.removeError(e)
+		return false
+	}
+	e.Severity = errors.severity(e)
+	errors.OEErrors = append(errors.OEErrors, &OEError{OE: item, Error: e})
+	return true
+}
+
+const (
+	NO_PARENT_ID            = "no parent id"
+	NON_EXISTING_PARENT     = "non-existing parent"
+	NO_PARENT_L_ID          = "no parent L id"
+	NON_EXISTING_PARENT_L   = "non-existing parent L"
+	NO_PARENT_F_ID          = "no parent F id"
+	NON_EXISTING_PARENT_F   = "non-existing parent F"
+	NO_RELATED_KU_ID        = "no related KU id"
+	NON_EXISTING_RELATED_KU = "non-existing related KU"
+	NO_RELATED_FS_ID        = "no related FS id"
+	NON_EXISTING_RELATED_FS = "non-existing related FS"
+	CYCLE_REFERENCE         = "cycle reference"
+	LEADS_INTO_CYCLE        = "leads into cycle"
+	CYCLE_REFERENCE_L       = "cycle reference in L hierarchy"
+	LEADS_INTO_CYCLE_L      = "leads into cycle in L hierarchy"
+	CYCLE_REFERENCE_F       = "cycle reference in F hierarchy"
+	LEADS_INTO_CYCLE_F      = "leads into cycle in F hierarchy"
+
+	INVALID_VALIDITY            = "validity ends before it starts"
+	VALIDITY_OUTSIDE_PARENT     = "validity outside of parent"
+	VALIDITY_OUTSIDE_PARENT_L   = "validity outside of parent L"
+	VALIDITY_OUTSIDE_PARENT_F   = "validity outside of parent F"
+	VALIDITY_OUTSIDE_RELATED_KU = "validity outside of related KU"
+	VALIDITY_OUTSIDE_RELATED_FS = "validity outside of related FS"
+)
+
+func analyzeTrees(oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) *Errors {
+	return analyzeTreesWith(defaultOptions, oeMap, kuMap, fsMap)
+}
+
+func analyzeTreesWith(options Options, oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) *Errors {
+	result := newErrors(options)
+
+	for _, v := range kuMap {
+		if v.ParentId != "" {
+			if _, ok := kuMap[v.ParentId]; !ok {
+				addKUError(v, addNonExistingReferenceError(&v.ItemWithError, NON_EXISTING_PARENT, v.ParentId), result)
+			}
+		}
+
+		if !v.validity().valid() {
+			addKUError(v, addInvalidValidityError(&v.ItemWithError, INVALID_VALIDITY), result)
+		} else if v.Parent != nil && v.Parent.validity().valid() && !v.Parent.validity().contains(v.validity()) {
+			addKUError(v, addValidityNotContainedError(&v.ItemWithError, VALIDITY_OUTSIDE_PARENT, v.ParentId), result)
+		}
+	}
+
+	for _, v := range fsMap {
+		if v.ParentId != "" {
+			if _, ok := fsMap[v.ParentId]; !ok {
+				addFSError(v, addNonExistingReferenceError(&v.ItemWithError, NON_EXISTING_PARENT, v.ParentId), result)
+			}
+		}
+
+		if !v.validity().valid() {
+			addFSError(v, addInvalidValidityError(&v.ItemWithError, INVALID_VALIDITY), result)
+		} else if v.Parent != nil && v.Parent.validity().valid() && !v.Parent.validity().contains(v.validity()) {
+			addFSError(v, addValidityNotContainedError(&v.ItemWithError, VALIDITY_OUTSIDE_PARENT, v.ParentId), result)
+		}
+	}
+
+	for _, v := range oeMap {
+		if v.ParentLId != "" {
+			if _, ok := oeMap[v.ParentLId]; !ok {
+				addOEError(v, addNonExistingReferenceError(&v.ItemWithError, NON_EXISTING_PARENT_L, v.ParentLId), result)
+			}
+		}
+
+		if v.ParentFId != "" {
+			if _, ok := oeMap[v.ParentFId]; !ok {
+				addOEError(v, addNonExistingReferenceError(&v.ItemWithError, NON_EXISTING_PARENT_F, v.ParentFId), result)
+			}
+		}
+
+		if v.KUId == "" {
+			addOEError(v, addMissingReferenceError(&v.ItemWithError, NO_RELATED_KU_ID), result)
+		} else if v.KU == nil {
+			addOEError(v, addNonExistingReferenceError(&v.ItemWithError, NON_EXISTING_RELATED_KU, v.KUId), result)
+		}
+
+		if v.FSId == "" {
+			addOEError(v, addMissingReferenceError(&v.ItemWithError, NO_RELATED_FS_ID), result)
+		} else if v.FS == nil {
+			addOEError(v, addNonExistingReferenceError(&v.ItemWithError, NON_EXISTING_RELATED_FS, v.FSId), result)
+		}
+
+		analyzeValidityOE(v, result)
+		analyzeConsistencyOE(v, result)
+		analyzeNamesOE(v, options.Names, result)
+	}
+
+	analyzeCyclesKU(kuMap, result)
+	analyzeCyclesFS(fsMap, result)
+	analyzeCyclesOE(oeMap, options, result)
+	analyzeDepthKU(kuMap, result)
+	analyzeDepthFS(fsMap, result)
+
+	result.sort()
+	return result
+}
+
+func analyzeValidityOE(v *OEItem, result *Errors) {
+	validity := v.validity()
+	if !validity.valid() {
+		addOEError(v, addInvalidValidityError(&v.ItemWithError, INVALID_VALIDITY), result)
+		return
+	}
+
+	if v.ParentL != nil && v.ParentL.validity().valid() && !v.ParentL.validity().contains(validity) {
+		addOEError(v, addValidityNotContainedError(&v.ItemWithError, VALIDITY_OUTSIDE_PARENT_L, v.ParentLId), result)
+	}
+
+	if v.ParentF != nil && v.ParentF.validity().valid() && !v.ParentF.validity().contains(validity) {
+		addOEError(v, addValidityNotContainedError(&v.ItemWithError, VALIDITY_OUTSIDE_PARENT_F, v.ParentFId), result)
+	}
+
+	if v.KU != nil && v.KU.validity().valid() && !v.KU.validity().contains(validity) {
+		addOEError(v, addValidityNotCoveredError(&v.ItemWithError, VALIDITY_OUTSIDE_RELATED_KU, v.KUId), result)
+	}
+
+	if v.FS != nil && v.FS.validity().valid() && !v.FS.validity().contains(validity) {
+		addOEError(v, addValidityNotCoveredError(&v.ItemWithError, VALIDITY_OUTSIDE_RELATED_FS, v.FSId), result)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package orgstruct
 
 import (
 	"testing"
@@ -123,6 +123,7 @@ func TestKUGood(t *testing.T) {
 	kuItem := &KUItem{
 		Id:       "ku1",
 		ParentId: "ku10",
+		Depth:    1,
 	}
 	kuMap[kuItem.Id] = kuItem
 
@@ -188,6 +189,7 @@ func TestFSGood(t *testing.T) {
 	fsItem := &FSItem{
 		Id:       "fs1",
 		ParentId: "fs10",
+		Depth:    1,
 	}
 	fsMap[fsItem.Id] = fsItem
 
@@ -276,6 +278,19 @@ func TestOENonExistingRefs(t *testing.T) {
 	assertError(Error{Message: NON_EXISTING_PARENT_F, Type: NonExistingReference}, oeItem.Errors, t)
 }
 
+func TestOENonExistingRelatedMessages(t *testing.T) {
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1", KUId: "ku1", FSId: "fs1"}}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	// the KU and FS checks have to be told apart in the output and in rules
+	assertError(Error{Message: "non-existing related KU", Type: NonExistingReference}, oeMap["oe1"].Errors, t)
+	assertError(Error{Message: "non-existing related FS", Type: NonExistingReference}, oeMap["oe1"].Errors, t)
+}
+
 func TestOEFullPicture(t *testing.T) {
 	kuMap := make(map[string]*KUItem)
 	fsMap := make(map[string]*FSItem)
@@ -341,6 +356,13 @@ func TestOECycle(t *testing.T) {
 	buildTrees(oeMap, kuMap, fsMap)
 	analyzeTrees(oeMap, kuMap, fsMap)
 
+	// a ring mixing L and F parents is no cycle in either hierarchy
+	assertErrorCount(0, oeItem1.Errors, t)
+	assertErrorCount(0, oeItem2.Errors, t)
+	assertErrorCount(0, oeItem3.Errors, t)
+
+	analyzeTreesWith(Options{MixedOECycles: true}, oeMap, kuMap, fsMap)
+
 	assertErrorCount(1, oeItem1.Errors, t)
 	assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, oeItem1.Errors, t)
 	assertErrorCount(1, oeItem2.Errors, t)
@@ -348,3 +370,131 @@ func TestOECycle(t *testing.T) {
 	assertErrorCount(1, oeItem3.Errors, t)
 	assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, oeItem3.Errors, t)
 }
+
+func TestErrorsReport(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuMap["ku2"] = &KUItem{Id: "ku2", ParentId: "ku10"}
+	kuMap["ku1"] = &KUItem{Id: "ku1", ParentId: "ku10"}
+	fsMap["fs1"] = &FSItem{Id: "fs1", ParentId: "fs10"}
+	oeMap["oe2"] = &OEItem{Id: "oe2"}
+	oeMap["oe1"] = &OEItem{Id: "oe1", KUId: "ku20", FSId: "fs1"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	if len(result.OEErrors) != 3 || len(result.KUErrors) != 2 || len(result.FSErrors) != 1 {
+		t.Errorf("wanted 3/2/1 errors, got: %d/%d/%d", len(result.OEErrors), len(result.KUErrors), len(result.FSErrors))
+	}
+
+	expected := []struct {
+		kind      string
+		id        string
+		errorType ErrorType
+	}{
+		{KindFS, "fs1", NonExistingReference},
+		{KindKU, "ku1", NonExistingReference},
+		{KindKU, "ku2", NonExistingReference},
+		{KindOE, "oe1", NonExistingReference},
+		{KindOE, "oe2", MissingReference},
+		{KindOE, "oe2", MissingReference},
+	}
+
+	findings := result.Findings()
+	if len(findings) != len(expected) {
+		t.Fatalf("wanted %d findings, got %d", len(expected), len(findings))
+	}
+
+	for i, e := range expected {
+		f := findings[i]
+		if f.Kind != e.kind || f.Id != e.id || f.Type != e.errorType {
+			t.Errorf("wanted finding %d to be %s %s %s, got: %s %s %s", i, e.kind, e.id, e.errorType, f.Kind, f.Id, f.Type)
+		}
+	}
+
+	oeFindings := result.Filter(func(f *Finding) bool {
+		return f.Kind == KindOE && f.Type == MissingReference
+	})
+	if len(oeFindings) != 2 {
+		t.Errorf("wanted 2 filtered findings, got %d", len(oeFindings))
+	}
+}
+
+func TestKUInvalidValidity(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuItem := &KUItem{
+		Id:    "ku1",
+		From:  parseTime("2020-01-01T00:00:00"),
+		Until: parseTime("2019-12-31T00:00:00"),
+	}
+	kuMap[kuItem.Id] = kuItem
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(1, kuItem.Errors, t)
+	assertError(Error{Message: INVALID_VALIDITY, Type: InvalidValidity}, kuItem.Errors, t)
+}
+
+func TestFSValidityOutsideParent(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	fsMap["fs10"] = &FSItem{
+		Id:    "fs10",
+		From:  parseTime("2000-01-01T00:00:00"),
+		Until: parseTime("2020-12-31T00:00:00"),
+	}
+
+	fsItem := &FSItem{
+		Id:       "fs1",
+		ParentId: "fs10",
+		Depth:    1,
+		From:     parseTime("2010-01-01T00:00:00"),
+		Until:    parseTime("9999-12-31T00:00:00"),
+	}
+	fsMap[fsItem.Id] = fsItem
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(1, fsItem.Errors, t)
+	assertError(Error{Message: VALIDITY_OUTSIDE_PARENT, Type: ValidityNotContained}, fsItem.Errors, t)
+	assertErrorCount(0, fsMap["fs10"].Errors, t)
+}
+
+func TestOEValidity(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuMap["ku1"] = &KUItem{Id: "ku1", From: parseTime("2005-01-01T00:00:00")}
+	fsMap["fs1"] = &FSItem{Id: "fs1", Until: parseTime("2015-12-31T00:00:00")}
+	oeMap["oe10"] = &OEItem{Id: "oe10", KUId: "ku1", FSId: "fs1", From: parseTime("2010-01-01T00:00:00")}
+	oeMap["oe20"] = &OEItem{Id: "oe20", KUId: "ku1", FSId: "fs1"}
+
+	oeItem := &OEItem{
+		Id:        "oe1",
+		ParentLId: "oe10",
+		ParentFId: "oe20",
+		KUId:      "ku1",
+		FSId:      "fs1",
+		From:      parseTime("2000-01-01T00:00:00"),
+		Until:     parseTime("2020-12-31T00:00:00"),
+	}
+	oeMap[oeItem.Id] = oeItem
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(3, oeItem.Errors, t)
+	assertError(Error{Message: VALIDITY_OUTSIDE_PARENT_L, Type: ValidityNotContained}, oeItem.Errors, t)
+	assertError(Error{Message: VALIDITY_OUTSIDE_RELATED_KU, Type: ValidityNotCovered}, oeItem.Errors, t)
+	assertError(Error{Message: VALIDITY_OUTSIDE_RELATED_FS, Type: ValidityNotCovered}, oeItem.Errors, t)
+}
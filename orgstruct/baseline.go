@@ -0,0 +1,175 @@
+package orgstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// BaselineEntry identifies an accepted finding. Findings are matched by kind,
+// id and type only, so changed messages or details do not break a baseline.
+type BaselineEntry struct {
+	Kind    string    `json:"kind"`
+	Id      string    `json:"id"`
+	Type    ErrorType `json:"type"`
+	Name    string    `json:"name,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+type baselineKey struct {
+	kind string
+	id   string
+	t    ErrorType
+}
+
+func (e *BaselineEntry) key() baselineKey {
+	return baselineKey{e.Kind, e.Id, e.Type}
+}
+
+// Baseline is the content of a baseline file.
+type Baseline struct {
+	Entries []*BaselineEntry `json:"entries"`
+}
+
+// BuildBaseline returns a baseline accepting all current findings.
+func BuildBaseline(errors *Errors) *Baseline {
+	b := &Baseline{Entries: []*BaselineEntry{}}
+	seen := make(map[baselineKey]bool)
+	for _, f := range errors.Findings() {
+		entry := &BaselineEntry{Kind: f.Kind, Id: f.Id, Type: f.Type, Name: f.Name, Message: f.Message}
+		if !seen[entry.key()] {
+			seen[entry.key()] = true
+			b.Entries = append(b.Entries, entry)
+		}
+	}
+	return b
+}
+
+// WriteBaseline writes the findings as a baseline in JSON.
+func WriteBaseline(w io.Writer, errors *Errors) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(BuildBaseline(errors))
+}
+
+// SaveBaseline writes the findings as a baseline file.
+func SaveBaseline(path string, errors *Errors) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteBaseline(file, errors); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ParseBaseline reads a baseline written by WriteBaseline.
+func ParseBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid baseline: %v", err)
+	}
+	return &b, nil
+}
+
+// LoadBaseline reads a baseline file written by SaveBaseline.
+func LoadBaseline(path string) (*Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	b, err := ParseBaseline(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return b, nil
+}
+
+// ApplyBaseline drops the errors accepted by the baseline, from the items as
+// well, and the cycles none of whose items has an error left. It records the
+// baseline entries without a matching error in Fixed.
+func (errors *Errors) ApplyBaseline(b *Baseline) {
+	accepted := make(map[baselineKey]bool)
+	for _, entry := range b.Entries {
+		accepted[entry.key()] = false
+	}
+
+	keep := func(kind, id string, t ErrorType) bool {
+		key := baselineKey{kind, id, t}
+		if _, ok := accepted[key]; ok {
+			accepted[key] = true
+			return false
+		}
+		return true
+	}
+
+	var oeErrors []*OEError
+	for _, e := range errors.OEErrors {
+		if keep(KindOE, e.OE.Id, e.Type) {
+			oeErrors = append(oeErrors, e)
+		} else {
+			e.OE.removeError(e.Error)
+		}
+	}
+	errors.OEErrors = oeErrors
+
+	var kuErrors []*KUError
+	for _, e := range errors.KUErrors {
+		if keep(KindKU, e.KU.Id, e.Type) {
+			kuErrors = append(kuErrors, e)
+		} else {
+			e.KU.removeError(e.Error)
+		}
+	}
+	errors.KUErrors = kuErrors
+
+	var fsErrors []*FSError
+	for _, e := range errors.FSErrors {
+		if keep(KindFS, e.FS.Id, e.Type) {
+			fsErrors = append(fsErrors, e)
+		} else {
+			e.FS.removeError(e.Error)
+		}
+	}
+	errors.FSErrors = fsErrors
+
+	reported := make(map[baselineKey]bool)
+	for _, f := range errors.Findings() {
+		reported[baselineKey{f.Kind, f.Id, f.Type}] = true
+	}
+	var cycles []*Cycle
+	for _, c := range errors.Cycles {
+		for _, id := range c.Ids {
+			if reported[baselineKey{c.Kind, id, c.errorType()}] {
+				cycles = append(cycles, c)
+				break
+			}
+		}
+	}
+	errors.Cycles = cycles
+
+	errors.Fixed = nil
+	for _, entry := range b.Entries {
+		if matched, ok := accepted[entry.key()]; ok && !matched {
+			errors.Fixed = append(errors.Fixed, entry)
+			delete(accepted, entry.key())
+		}
+	}
+	sort.SliceStable(errors.Fixed, func(i, j int) bool {
+		a, b := errors.Fixed[i], errors.Fixed[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Id != b.Id {
+			return a.Id < b.Id
+		}
+		return a.Type < b.Type
+	})
+}
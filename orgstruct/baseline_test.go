@@ -0,0 +1,96 @@
+package orgstruct
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBaselineRoundTrip(t *testing.T) {
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1", OrgKZ: "I.SV-O"}}
+	buildTrees(oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+	result := analyzeTrees(oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+
+	var buffer bytes.Buffer
+	if err := WriteBaseline(&buffer, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	b, err := ParseBaseline(&buffer)
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	// both OE errors are MissingReference, so they share one entry
+	if len(b.Entries) != 1 {
+		t.Fatalf("wanted 1 entry, got: %d", len(b.Entries))
+	}
+
+	entry := b.Entries[0]
+	if entry.Kind != KindOE || entry.Id != "oe1" || entry.Type != MissingReference || entry.Name != "I.SV-O" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	result.ApplyBaseline(b)
+	if result.Count() != 0 || len(result.Fixed) != 0 {
+		t.Errorf("wanted no errors and nothing fixed, got: %d, %d", result.Count(), len(result.Fixed))
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1", ParentId: "ku10"}}
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1"}}
+	buildTrees(oeMap, kuMap, map[string]*FSItem{})
+	result := analyzeTrees(oeMap, kuMap, map[string]*FSItem{})
+
+	b := &Baseline{Entries: []*BaselineEntry{
+		{Kind: KindOE, Id: "oe1", Type: MissingReference},
+		{Kind: KindOE, Id: "oe2", Type: MissingReference},
+		{Kind: KindKU, Id: "ku1", Type: MissingReference},
+	}}
+	result.ApplyBaseline(b)
+
+	if len(result.OEErrors) != 0 {
+		t.Errorf("wanted no OE errors, got: %d", len(result.OEErrors))
+	}
+	assertErrorCount(0, oeMap["oe1"].Errors, t)
+	assertErrorCount(1, kuMap["ku1"].Errors, t)
+
+	if len(result.KUErrors) != 1 || result.KUErrors[0].Type != NonExistingReference {
+		t.Errorf("wanted the non-existing parent of ku1, got: %d errors", len(result.KUErrors))
+	}
+
+	if len(result.Fixed) != 2 || result.Fixed[0].Id != "ku1" || result.Fixed[1].Id != "oe2" {
+		t.Errorf("wanted ku1 and oe2 fixed, got: %d", len(result.Fixed))
+	}
+}
+
+func TestApplyBaselineCycle(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1", ParentId: "ku2", Depth: 1},
+		"ku2": {Id: "ku2", ParentId: "ku1", Depth: 1},
+		"ku3": {Id: "ku3", ParentId: "ku4", Depth: 1},
+		"ku4": {Id: "ku4", ParentId: "ku3", Depth: 1},
+	}
+	buildTrees(map[string]*OEItem{}, kuMap, map[string]*FSItem{})
+	result := analyzeTrees(map[string]*OEItem{}, kuMap, map[string]*FSItem{})
+
+	result.ApplyBaseline(&Baseline{Entries: []*BaselineEntry{
+		{Kind: KindKU, Id: "ku1", Type: CycleError},
+		{Kind: KindKU, Id: "ku2", Type: CycleError},
+		{Kind: KindKU, Id: "ku3", Type: CycleError},
+	}})
+
+	if len(result.Cycles) != 1 || result.Cycles[0].Ids[0] != "ku3" {
+		t.Fatalf("wanted only the cycle of ku3 and ku4, got: %d cycles", len(result.Cycles))
+	}
+	assertErrorCount(0, kuMap["ku1"].Errors, t)
+	assertErrorCount(0, kuMap["ku3"].Errors, t)
+	assertErrorCount(1, kuMap["ku4"].Errors, t)
+}
+
+func TestParseBaselineUnknownType(t *testing.T) {
+	_, err := ParseBaseline(bytes.NewBufferString(`{"entries": [{"kind": "OE", "id": "oe1", "type": "NoSuchType"}]}`))
+	if err == nil {
+		t.Errorf("wanted error, got none")
+	}
+}
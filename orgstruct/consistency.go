@@ -0,0 +1,64 @@
+package orgstruct
+
+import (
+	"fmt"
+)
+
+const (
+	PARENT_L_KU_MISMATCH = "parent L belongs to unrelated KU"
+	PARENT_F_FS_MISMATCH = "parent F belongs to unrelated FS"
+)
+
+func addParentKUMismatchError(item *ItemWithError, message string, reference string, detail string) *Error {
+	e := &Error{Message: message, Type: ParentKUMismatch, Reference: reference, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addParentFSMismatchError(item *ItemWithError, message string, reference string, detail string) *Error {
+	e := &Error{Message: message, Type: ParentFSMismatch, Reference: reference, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+// isKUAncestorOrSelf reports whether ancestor is item itself or one of its
+// parents. It stops at cycles.
+func isKUAncestorOrSelf(ancestor *KUItem, item *KUItem) bool {
+	visited := make(map[*KUItem]bool)
+	for ; item != nil && !visited[item]; item = item.Parent {
+		if item == ancestor {
+			return true
+		}
+		visited[item] = true
+	}
+	return false
+}
+
+// isFSAncestorOrSelf reports whether ancestor is item itself or one of its
+// parents. It stops at cycles.
+func isFSAncestorOrSelf(ancestor *FSItem, item *FSItem) bool {
+	visited := make(map[*FSItem]bool)
+	for ; item != nil && !visited[item]; item = item.Parent {
+		if item == ancestor {
+			return true
+		}
+		visited[item] = true
+	}
+	return false
+}
+
+// analyzeConsistencyOE checks that the parents of an OE item agree with its
+// KU and FS: the L parent has to belong to the KU of the item or one of its
+// parents, and the FS of the F parent has to be the FS of the item or one of
+// its parents. Missing links are reported elsewhere.
+func analyzeConsistencyOE(v *OEItem, result *Errors) {
+	if v.ParentL != nil && v.ParentL.KU != nil && v.KU != nil && !isKUAncestorOrSelf(v.ParentL.KU, v.KU) {
+		detail := fmt.Sprintf("KU %s (%s) of OE, KU %s (%s) of parent L", v.KU.Id, v.KU.NameLong, v.ParentL.KU.Id, v.ParentL.KU.NameLong)
+		addOEError(v, addParentKUMismatchError(&v.ItemWithError, PARENT_L_KU_MISMATCH, v.ParentLId, detail), result)
+	}
+
+	if v.ParentF != nil && v.ParentF.FS != nil && v.FS != nil && !isFSAncestorOrSelf(v.ParentF.FS, v.FS) {
+		detail := fmt.Sprintf("FS %s (%s) of OE, FS %s (%s) of parent F", v.FS.Id, v.FS.NameLong, v.ParentF.FS.Id, v.ParentF.FS.NameLong)
+		addOEError(v, addParentFSMismatchError(&v.ItemWithError, PARENT_F_FS_MISMATCH, v.ParentFId, detail), result)
+	}
+}
@@ -0,0 +1,56 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestOEParentConsistency(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuMap["ku1"] = &KUItem{Id: "ku1", NameLong: "Konzern"}
+	kuMap["ku2"] = &KUItem{Id: "ku2", ParentId: "ku1", NameLong: "Tochter"}
+	kuMap["ku3"] = &KUItem{Id: "ku3", ParentId: "ku1", NameLong: "Schwester"}
+	fsMap["fs1"] = &FSItem{Id: "fs1", NameLong: "Vorstand"}
+	fsMap["fs2"] = &FSItem{Id: "fs2", ParentId: "fs1", NameLong: "Bereich"}
+
+	oeMap["oe1"] = &OEItem{Id: "oe1", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe2"] = &OEItem{Id: "oe2", KUId: "ku2", FSId: "fs2", ParentLId: "oe1", ParentFId: "oe1"}
+	oeMap["oe3"] = &OEItem{Id: "oe3", KUId: "ku3", FSId: "fs1", ParentLId: "oe2", ParentFId: "oe2"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(0, oeMap["oe1"].Errors, t)
+	assertErrorCount(0, oeMap["oe2"].Errors, t)
+
+	errors := oeMap["oe3"].Errors
+	assertErrorCount(2, errors, t)
+	assertError(Error{Message: PARENT_L_KU_MISMATCH, Type: ParentKUMismatch}, errors, t)
+	assertError(Error{Message: PARENT_F_FS_MISMATCH, Type: ParentFSMismatch}, errors, t)
+
+	for _, e := range errors {
+		if e.Reference != "oe2" {
+			t.Errorf("wanted reference to oe2, got: %s", e.Reference)
+		}
+		if e.Type == ParentKUMismatch && e.Detail != "KU ku3 (Schwester) of OE, KU ku2 (Tochter) of parent L" {
+			t.Errorf("unexpected detail: %s", e.Detail)
+		}
+	}
+}
+
+func TestIsKUAncestorOrSelfStopsAtCycles(t *testing.T) {
+	ku1 := &KUItem{Id: "ku1"}
+	ku2 := &KUItem{Id: "ku2", Parent: ku1}
+	ku1.Parent = ku2
+	other := &KUItem{Id: "other"}
+
+	if !isKUAncestorOrSelf(ku1, ku2) || !isKUAncestorOrSelf(ku2, ku2) {
+		t.Errorf("wanted ku1 and ku2 to be ancestors of ku2")
+	}
+
+	if isKUAncestorOrSelf(other, ku2) {
+		t.Errorf("wanted other not to be an ancestor of ku2")
+	}
+}
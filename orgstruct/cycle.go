@@ -0,0 +1,597 @@
+package orgstruct
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	HierarchyL     = "L"
+	HierarchyF     = "F"
+	HierarchyMixed = "L+F"
+)
+
+// Cycle is a ring of items, each referencing the next one as its parent and
+// the last one referencing the first. It starts at the item with the lowest
+// id, so that the same ring found from different items compares equal. For
+// OE items, Hierarchy tells which parents were followed.
+type Cycle struct {
+	Kind      string
+	Hierarchy string
+	Ids       []string
+	Names     []string
+}
+
+func newCycle(kind string, hierarchy string, ids []string, names []string) *Cycle {
+	start := 0
+	for i, id := range ids {
+		if id < ids[start] {
+			start = i
+		}
+	}
+
+	c := &Cycle{Kind: kind, Hierarchy: hierarchy}
+	c.Ids = append(append(c.Ids, ids[start:]...), ids[:start]...)
+	c.Names = append(append(c.Names, names[start:]...), names[:start]...)
+	return c
+}
+
+func (c *Cycle) key() string {
+	return c.Kind + ":" + c.Hierarchy + ":" + strings.Join(c.Ids, ",")
+}
+
+// errorType returns the type of the errors reported on the items of c.
+func (c *Cycle) errorType() ErrorType {
+	switch c.Hierarchy {
+	case HierarchyL:
+		return CycleL
+	case HierarchyF:
+		return CycleF
+	}
+	return CycleError
+}
+
+func (c *Cycle) String() string {
+	parts := make([]string, 0, len(c.Ids)+1)
+	for i, id := range c.Ids {
+		if c.Names[i] != "" {
+			id += " (" + c.Names[i] + ")"
+		}
+		parts = append(parts, id)
+	}
+	parts = append(parts, parts[0])
+	return strings.Join(parts, " -> ")
+}
+
+// addCycle records c unless the same ring is already known and returns the
+// recorded cycle.
+func (errors *Errors) addCycle(c *Cycle) *Cycle {
+	if errors.cycles == nil {
+		errors.cycles = make(map[string]*Cycle)
+	}
+
+	if existing, ok := errors.cycles[c.key()]; ok {
+		return existing
+	}
+
+	errors.cycles[c.key()] = c
+	errors.Cycles = append(errors.Cycles, c)
+	return c
+}
+
+func addCycleError(item *ItemWithError, errorType ErrorType, message string, detail string) *Error {
+	e := &Error{Message: message, Type: errorType, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addCycleLeadInError(item *ItemWithError, errorType ErrorType, message string, reference string, detail string) *Error {
+	e := &Error{Message: message, Type: errorType, Reference: reference, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+// cycles is the result of findCycles for a graph of n nodes.
+type cycles struct {
+	// rings holds the distinct rings found, each in parent order.
+	rings [][]int
+	// ring is the index of the ring each node is part of, or -1.
+	ring []int
+	// entry is the node of a ring that a node leads into, or -1.
+	entry []int
+	// search returns a ring through a node of a component that is not a
+	// simple ring, or nil.
+	search func(start int, inComponent func(int) bool) []int
+}
+
+// findCycles finds the rings and the nodes leading into them in a graph
+// whose edges point from a node to its parents. It runs Tarjan's strongly
+// connected components algorithm once over the whole graph, so it takes
+// linear time as long as each component is a simple ring, which is always
+// the case for nodes with a single parent. For other components, a shortest
+// ring through each of their nodes is searched.
+func findCycles(parents [][]int) *cycles {
+	return findCyclesWith(parents, func(start int, inComponent func(int) bool) []int {
+		return shortestRing(parents, start, inComponent)
+	})
+}
+
+// findMixedCycles finds the rings of the graph of both kinds of OE parents
+// that follow at least one L and one F parent. Every node on such a ring is
+// a member, also if its own shortest ring lies in one hierarchy only.
+func findMixedCycles(lParents [][]int, fParents [][]int, bothParents [][]int) *cycles {
+	found := findCyclesWith(bothParents, func(start int, inComponent func(int) bool) []int {
+		return mixedRing(lParents, fParents, start, inComponent)
+	})
+	found.keepRings(func(ring []int) bool {
+		return !inHierarchy(ring, lParents) && !inHierarchy(ring, fParents)
+	})
+	return found
+}
+
+func findCyclesWith(parents [][]int, search func(start int, inComponent func(int) bool) []int) *cycles {
+	n := len(parents)
+	result := &cycles{ring: make([]int, n), entry: make([]int, n), search: search}
+	for i := range result.ring {
+		result.ring[i] = -1
+		result.entry[i] = -1
+	}
+
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	var stack []int
+	counter := 0
+
+	type frame struct {
+		node int
+		edge int
+	}
+	var calls []frame
+
+	visit := func(v int) {
+		counter++
+		index[v], low[v] = counter, counter
+		stack = append(stack, v)
+		onStack[v] = true
+		calls = append(calls, frame{node: v})
+	}
+
+	for start := 0; start < n; start++ {
+		if index[start] != 0 {
+			continue
+		}
+
+		visit(start)
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			v := f.node
+
+			if f.edge < len(parents[v]) {
+				w := parents[v][f.edge]
+				f.edge++
+				if index[w] == 0 {
+					visit(w)
+				} else if onStack[w] && index[w] < low[v] {
+					low[v] = index[w]
+				}
+				continue
+			}
+
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				u := calls[len(calls)-1].node
+				if low[v] < low[u] {
+					low[u] = low[v]
+				}
+			}
+
+			if low[v] != index[v] {
+				continue
+			}
+
+			i := len(stack) - 1
+			for stack[i] != v {
+				i--
+			}
+			component := stack[i:]
+			stack = stack[:i]
+			for _, w := range component {
+				onStack[w] = false
+			}
+
+			// components are completed after all components reachable from
+			// them, so the parents of v have already been dealt with
+			result.addComponent(parents, component, index[v])
+		}
+	}
+
+	return result
+}
+
+func (c *cycles) addComponent(parents [][]int, component []int, id int) {
+	if len(component) == 1 {
+		v := component[0]
+		for _, w := range parents[v] {
+			if w == v {
+				c.addRing([]int{v})
+				return
+			}
+		}
+
+		for _, w := range parents[v] {
+			if c.ring[w] >= 0 {
+				c.entry[v] = w
+				return
+			}
+			if c.entry[w] >= 0 {
+				c.entry[v] = c.entry[w]
+				return
+			}
+		}
+		return
+	}
+
+	// mark the members, so that edges within the component can be told apart
+	for _, v := range component {
+		c.entry[v] = -2 - id
+	}
+	inComponent := func(w int) bool {
+		return c.entry[w] == -2-id
+	}
+
+	simple := true
+	for _, v := range component {
+		count := 0
+		for _, w := range parents[v] {
+			if inComponent(w) {
+				count++
+			}
+		}
+		simple = simple && count == 1
+	}
+
+	if simple {
+		ring := make([]int, 0, len(component))
+		v := component[0]
+		for len(ring) < len(component) {
+			ring = append(ring, v)
+			for _, w := range parents[v] {
+				if inComponent(w) {
+					v = w
+					break
+				}
+			}
+		}
+		c.addRing(ring)
+	} else {
+		first := len(c.rings)
+		for _, v := range component {
+			if ring := c.search(v, inComponent); ring != nil {
+				c.ring[v] = len(c.rings)
+				c.rings = append(c.rings, ring)
+			}
+		}
+		// nodes without a ring of their own are members of the rings found
+		// through the other nodes
+		for i := first; i < len(c.rings); i++ {
+			for _, w := range c.rings[i] {
+				if c.ring[w] < 0 {
+					c.ring[w] = i
+				}
+			}
+		}
+	}
+
+	for _, v := range component {
+		c.entry[v] = -1
+	}
+
+	// the remaining nodes lead into a ring of the component
+	for changed := true; changed; {
+		changed = false
+		for _, v := range component {
+			if c.ring[v] >= 0 || c.entry[v] >= 0 {
+				continue
+			}
+			for _, w := range parents[v] {
+				if c.ring[w] >= 0 {
+					c.entry[v] = w
+				} else if c.entry[w] >= 0 {
+					c.entry[v] = c.entry[w]
+				}
+				if c.entry[v] >= 0 {
+					changed = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// keepRings drops the rings for which keep returns false, together with the
+// nodes leading into them. Dropped rings are left as nil in rings.
+func (c *cycles) keepRings(keep func([]int) bool) {
+	dropped := make([]bool, len(c.rings))
+	for i, ring := range c.rings {
+		dropped[i] = !keep(ring)
+	}
+
+	for v := range c.ring {
+		if entry := c.entry[v]; entry >= 0 && dropped[c.ring[entry]] {
+			c.entry[v] = -1
+		}
+	}
+	for v, ring := range c.ring {
+		if ring >= 0 && dropped[ring] {
+			c.ring[v] = -1
+		}
+	}
+	for i := range c.rings {
+		if dropped[i] {
+			c.rings[i] = nil
+		}
+	}
+}
+
+func (c *cycles) addRing(ring []int) {
+	for _, v := range ring {
+		c.ring[v] = len(c.rings)
+	}
+	c.rings = append(c.rings, ring)
+}
+
+// shortestRing searches the shortest ring through start using only nodes
+// for which inComponent returns true.
+func shortestRing(parents [][]int, start int, inComponent func(int) bool) []int {
+	previous := map[int]int{start: -1}
+	queue := []int{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range parents[v] {
+			if w == start {
+				var ring []int
+				for ; v != -1; v = previous[v] {
+					ring = append(ring, v)
+				}
+				for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+					ring[i], ring[j] = ring[j], ring[i]
+				}
+				return ring
+			}
+			if _, seen := previous[w]; !seen && inComponent(w) {
+				previous[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil
+}
+
+// cycleRule describes how cycles in one hierarchy are reported.
+type cycleRule struct {
+	kind          string
+	hierarchy     string
+	errorType     ErrorType
+	message       string
+	leadInType    ErrorType
+	leadInMessage string
+}
+
+var (
+	cycleRuleFS     = cycleRule{KindFS, "", CycleError, CYCLE_REFERENCE, CycleLeadIn, LEADS_INTO_CYCLE}
+	cycleRuleKU     = cycleRule{KindKU, "", CycleError, CYCLE_REFERENCE, CycleLeadIn, LEADS_INTO_CYCLE}
+	cycleRuleOEL    = cycleRule{KindOE, HierarchyL, CycleL, CYCLE_REFERENCE_L, CycleLeadInL, LEADS_INTO_CYCLE_L}
+	cycleRuleOEF    = cycleRule{KindOE, HierarchyF, CycleF, CYCLE_REFERENCE_F, CycleLeadInF, LEADS_INTO_CYCLE_F}
+	cycleRuleOEBoth = cycleRule{KindOE, HierarchyMixed, CycleError, CYCLE_REFERENCE, CycleLeadIn, LEADS_INTO_CYCLE}
+)
+
+// analyzeCycles reports every node of a hierarchy that is part of a ring
+// found, and every node that leads into one, on the errors returned by
+// errorsOf. A ring is only recorded if the error of one of its nodes is
+// reported, so that rules disabling or scoping the check also apply to it.
+func analyzeCycles(rule cycleRule, ids []string, names []string, found *cycles, errorsOf func(int) *ItemWithError, add func(int, *Error) bool, result *Errors) {
+	rings := make([]*Cycle, len(found.rings))
+	for i, ring := range found.rings {
+		if ring == nil {
+			continue
+		}
+		ringIds := make([]string, len(ring))
+		ringNames := make([]string, len(ring))
+		for j, v := range ring {
+			ringIds[j], ringNames[j] = ids[v], names[v]
+		}
+		rings[i] = newCycle(rule.kind, rule.hierarchy, ringIds, ringNames)
+	}
+
+	reported := make([]bool, len(found.rings))
+	for v := range found.ring {
+		if ring := found.ring[v]; ring >= 0 {
+			if add(v, addCycleError(errorsOf(v), rule.errorType, rule.message, rings[ring].String())) {
+				reported[ring] = true
+			}
+		} else if entry := found.entry[v]; entry >= 0 {
+			add(v, addCycleLeadInError(errorsOf(v), rule.leadInType, rule.leadInMessage, ids[entry], rings[found.ring[entry]].String()))
+		}
+	}
+
+	for i, c := range rings {
+		if reported[i] {
+			result.addCycle(c)
+		}
+	}
+}
+
+func analyzeCyclesFS(fsMap map[string]*FSItem, result *Errors) {
+	items := make([]*FSItem, 0, len(fsMap))
+	for _, v := range fsMap {
+		items = append(items, v)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Id < items[j].Id
+	})
+
+	index := make(map[*FSItem]int, len(items))
+	ids := make([]string, len(items))
+	names := make([]string, len(items))
+	for i, v := range items {
+		index[v] = i
+		ids[i], names[i] = v.Id, v.NameLong
+	}
+
+	parents := make([][]int, len(items))
+	for i, v := range items {
+		if p, ok := index[v.Parent]; ok {
+			parents[i] = []int{p}
+		}
+	}
+
+	analyzeCycles(cycleRuleFS, ids, names, findCycles(parents), func(i int) *ItemWithError {
+		return &items[i].ItemWithError
+	}, func(i int, e *Error) bool {
+		return addFSError(items[i], e, result)
+	}, result)
+}
+
+func analyzeCyclesKU(kuMap map[string]*KUItem, result *Errors) {
+	items := make([]*KUItem, 0, len(kuMap))
+	for _, v := range kuMap {
+		items = append(items, v)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Id < items[j].Id
+	})
+
+	index := make(map[*KUItem]int, len(items))
+	ids := make([]string, len(items))
+	names := make([]string, len(items))
+	for i, v := range items {
+		index[v] = i
+		ids[i], names[i] = v.Id, v.NameLong
+	}
+
+	parents := make([][]int, len(items))
+	for i, v := range items {
+		if p, ok := index[v.Parent]; ok {
+			parents[i] = []int{p}
+		}
+	}
+
+	analyzeCycles(cycleRuleKU, ids, names, findCycles(parents), func(i int) *ItemWithError {
+		return &items[i].ItemWithError
+	}, func(i int, e *Error) bool {
+		return addKUError(items[i], e, result)
+	}, result)
+}
+
+// mixedRing searches the shortest ring through start that follows at least
+// one L and one F parent, using only nodes for which inComponent returns
+// true. It returns nil if there is none or if the shortest one passes a node
+// twice.
+func mixedRing(lParents [][]int, fParents [][]int, start int, inComponent func(int) bool) []int {
+	// a state is a node together with the kinds of parents followed to it
+	type state struct {
+		node  int
+		kinds int
+	}
+	const followedL, followedF = 1, 2
+
+	previous := map[state]state{{start, 0}: {-1, 0}}
+	queue := []state{{start, 0}}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, edges := range []struct {
+			parents [][]int
+			kind    int
+		}{{lParents, followedL}, {fParents, followedF}} {
+			for _, w := range edges.parents[s.node] {
+				next := state{w, s.kinds | edges.kind}
+				if w == start && next.kinds == followedL|followedF {
+					var ring []int
+					seen := make(map[int]bool)
+					for v := s; v.node != -1; v = previous[v] {
+						if seen[v.node] {
+							return nil
+						}
+						seen[v.node] = true
+						ring = append(ring, v.node)
+					}
+					for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+						ring[i], ring[j] = ring[j], ring[i]
+					}
+					return ring
+				}
+				if _, ok := previous[next]; !ok && w != start && inComponent(w) {
+					previous[next] = s
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inHierarchy tells whether every edge of a ring is one of the parents.
+func inHierarchy(ring []int, parents [][]int) bool {
+	for i, v := range ring {
+		next := ring[(i+1)%len(ring)]
+		found := false
+		for _, w := range parents[v] {
+			found = found || w == next
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeCyclesOE checks the L and F hierarchies of the OE items separately
+// and, if enabled, the graph of both kinds of parents combined.
+func analyzeCyclesOE(oeMap map[string]*OEItem, options Options, result *Errors) {
+	items := make([]*OEItem, 0, len(oeMap))
+	for _, v := range oeMap {
+		items = append(items, v)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Id < items[j].Id
+	})
+
+	index := make(map[*OEItem]int, len(items))
+	ids := make([]string, len(items))
+	names := make([]string, len(items))
+	for i, v := range items {
+		index[v] = i
+		ids[i], names[i] = v.Id, v.OrgKZ
+	}
+
+	lParents := make([][]int, len(items))
+	fParents := make([][]int, len(items))
+	bothParents := make([][]int, len(items))
+	for i, v := range items {
+		if p, ok := index[v.ParentL]; ok {
+			lParents[i] = []int{p}
+			bothParents[i] = append(bothParents[i], p)
+		}
+		if p, ok := index[v.ParentF]; ok {
+			fParents[i] = []int{p}
+			bothParents[i] = append(bothParents[i], p)
+		}
+	}
+
+	errorsOf := func(i int) *ItemWithError {
+		return &items[i].ItemWithError
+	}
+	add := func(i int, e *Error) bool {
+		return addOEError(items[i], e, result)
+	}
+
+	analyzeCycles(cycleRuleOEL, ids, names, findCycles(lParents), errorsOf, add, result)
+	analyzeCycles(cycleRuleOEF, ids, names, findCycles(fParents), errorsOf, add, result)
+	if options.MixedOECycles {
+		// rings lying in one hierarchy only are already reported above
+		analyzeCycles(cycleRuleOEBoth, ids, names, findMixedCycles(lParents, fParents, bothParents), errorsOf, add, result)
+	}
+}
@@ -0,0 +1,317 @@
+package orgstruct
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestNewCycleStartsAtLowestId(t *testing.T) {
+	c := newCycle(KindOE, HierarchyL, []string{"oe3", "oe1", "oe2"}, []string{"C", "A", ""})
+
+	if c.String() != "oe1 (A) -> oe2 -> oe3 (C) -> oe1 (A)" {
+		t.Errorf("unexpected cycle: %s", c)
+	}
+}
+
+func TestKUCyclePath(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuMap["ku1"] = &KUItem{Id: "ku1", ParentId: "ku2", NameLong: "one"}
+	kuMap["ku2"] = &KUItem{Id: "ku2", ParentId: "ku3", NameLong: "two"}
+	kuMap["ku3"] = &KUItem{Id: "ku3", ParentId: "ku2", NameLong: "three"}
+	kuMap["ku4"] = &KUItem{Id: "ku4", ParentId: "ku1", NameLong: "four"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	if len(result.Cycles) != 1 {
+		t.Fatalf("wanted 1 distinct cycle, got: %d", len(result.Cycles))
+	}
+
+	ring := "ku2 (two) -> ku3 (three) -> ku2 (two)"
+	if result.Cycles[0].String() != ring {
+		t.Errorf("wanted cycle %s, got: %s", ring, result.Cycles[0])
+	}
+
+	for _, id := range []string{"ku2", "ku3"} {
+		item := kuMap[id]
+		assertErrorCount(1, item.Errors, t)
+		assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, item.Errors, t)
+		if item.Errors[0].Detail != ring {
+			t.Errorf("wanted detail %s for %s, got: %s", ring, id, item.Errors[0].Detail)
+		}
+	}
+
+	for _, id := range []string{"ku1", "ku4"} {
+		item := kuMap[id]
+		assertErrorCount(1, item.Errors, t)
+		assertError(Error{Message: LEADS_INTO_CYCLE, Type: CycleLeadIn}, item.Errors, t)
+		if item.Errors[0].Reference != "ku2" {
+			t.Errorf("wanted %s to lead into the cycle at ku2, got: %s", id, item.Errors[0].Reference)
+		}
+	}
+}
+
+func TestFSSelfReference(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	fsMap["fs1"] = &FSItem{Id: "fs1", ParentId: "fs1"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(1, fsMap["fs1"].Errors, t)
+	assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, fsMap["fs1"].Errors, t)
+	if len(result.Cycles) != 1 || result.Cycles[0].String() != "fs1 -> fs1" {
+		t.Errorf("wanted cycle fs1 -> fs1, got: %d cycles", len(result.Cycles))
+	}
+}
+
+func TestOEMixedCyclePathWithOrgKZ(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1"}}
+	oeMap := make(map[string]*OEItem)
+
+	oeMap["oe1"] = &OEItem{Id: "oe1", ParentLId: "oe2", KUId: "ku1", FSId: "fs1", OrgKZ: "A"}
+	oeMap["oe2"] = &OEItem{Id: "oe2", ParentFId: "oe1", KUId: "ku1", FSId: "fs1", OrgKZ: "B"}
+	oeMap["oe3"] = &OEItem{Id: "oe3", ParentLId: "oe2", KUId: "ku1", FSId: "fs1", OrgKZ: "C"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTreesWith(Options{MixedOECycles: true}, oeMap, kuMap, fsMap)
+
+	if len(result.Cycles) != 1 || result.Cycles[0].String() != "oe1 (A) -> oe2 (B) -> oe1 (A)" {
+		t.Fatalf("wanted cycle oe1 (A) -> oe2 (B) -> oe1 (A), got: %d cycles", len(result.Cycles))
+	}
+
+	assertError(Error{Message: LEADS_INTO_CYCLE, Type: CycleLeadIn}, oeMap["oe3"].Errors, t)
+	assertErrorCount(1, oeMap["oe3"].Errors, t)
+}
+
+func TestFindCycles(t *testing.T) {
+	// 0 -> 1 -> 2 -> 1, 3 -> 0, 4 -> 4, 5 -> 6
+	parents := [][]int{{1}, {2}, {1}, {0}, {4}, {6}, nil}
+	found := findCycles(parents)
+
+	if len(found.rings) != 2 {
+		t.Fatalf("wanted 2 rings, got: %d", len(found.rings))
+	}
+
+	expectedRing := []bool{false, true, true, false, true, false, false}
+	expectedEntry := []int{1, -1, -1, 1, -1, -1, -1}
+	for v := range parents {
+		if (found.ring[v] >= 0) != expectedRing[v] {
+			t.Errorf("wanted node %d in ring: %t", v, expectedRing[v])
+		}
+		if found.entry[v] != expectedEntry[v] {
+			t.Errorf("wanted node %d to lead into %d, got: %d", v, expectedEntry[v], found.entry[v])
+		}
+	}
+
+	if ring := found.rings[found.ring[4]]; len(ring) != 1 || ring[0] != 4 {
+		t.Errorf("wanted self reference of node 4, got: %v", ring)
+	}
+}
+
+func TestOEMixedCyclesWithSharedNode(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1"}}
+	oeMap := make(map[string]*OEItem)
+
+	oeMap["oe1"] = &OEItem{Id: "oe1", ParentFId: "oe2", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe2"] = &OEItem{Id: "oe2", ParentLId: "oe1", ParentFId: "oe3", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe3"] = &OEItem{Id: "oe3", ParentLId: "oe2", KUId: "ku1", FSId: "fs1"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTreesWith(Options{MixedOECycles: true}, oeMap, kuMap, fsMap)
+
+	if len(result.Cycles) != 2 {
+		t.Fatalf("wanted 2 distinct cycles, got: %d", len(result.Cycles))
+	}
+
+	if result.Cycles[0].String() != "oe1 -> oe2 -> oe1" || result.Cycles[1].String() != "oe2 -> oe3 -> oe2" {
+		t.Errorf("unexpected cycles: %s, %s", result.Cycles[0], result.Cycles[1])
+	}
+
+	for _, id := range []string{"oe1", "oe2", "oe3"} {
+		assertErrorCount(1, oeMap[id].Errors, t)
+		assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, oeMap[id].Errors, t)
+	}
+}
+
+func TestOESeparateHierarchies(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1"}}
+	oeMap := make(map[string]*OEItem)
+
+	// oe1 and oe2 form a cycle in L, oe3 and oe4 in F, and up via L from oe5
+	// and back down via F is no cycle in either hierarchy
+	oeMap["oe1"] = &OEItem{Id: "oe1", ParentLId: "oe2", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe2"] = &OEItem{Id: "oe2", ParentLId: "oe1", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe3"] = &OEItem{Id: "oe3", ParentFId: "oe4", ParentLId: "oe1", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe4"] = &OEItem{Id: "oe4", ParentFId: "oe3", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe5"] = &OEItem{Id: "oe5", ParentLId: "oe6", KUId: "ku1", FSId: "fs1"}
+	oeMap["oe6"] = &OEItem{Id: "oe6", ParentFId: "oe5", KUId: "ku1", FSId: "fs1"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	if len(result.Cycles) != 2 {
+		t.Fatalf("wanted 2 distinct cycles, got: %d", len(result.Cycles))
+	}
+
+	if result.Cycles[0].Hierarchy != HierarchyF || result.Cycles[0].String() != "oe3 -> oe4 -> oe3" {
+		t.Errorf("wanted F cycle oe3 -> oe4 -> oe3, got: %s %s", result.Cycles[0].Hierarchy, result.Cycles[0])
+	}
+
+	if result.Cycles[1].Hierarchy != HierarchyL || result.Cycles[1].String() != "oe1 -> oe2 -> oe1" {
+		t.Errorf("wanted L cycle oe1 -> oe2 -> oe1, got: %s %s", result.Cycles[1].Hierarchy, result.Cycles[1])
+	}
+
+	assertErrorCount(1, oeMap["oe1"].Errors, t)
+	assertError(Error{Message: CYCLE_REFERENCE_L, Type: CycleL}, oeMap["oe1"].Errors, t)
+	assertErrorCount(2, oeMap["oe3"].Errors, t)
+	assertError(Error{Message: CYCLE_REFERENCE_F, Type: CycleF}, oeMap["oe3"].Errors, t)
+	assertError(Error{Message: LEADS_INTO_CYCLE_L, Type: CycleLeadInL}, oeMap["oe3"].Errors, t)
+	assertErrorCount(0, oeMap["oe5"].Errors, t)
+	assertErrorCount(0, oeMap["oe6"].Errors, t)
+
+	result = analyzeTreesWith(Options{MixedOECycles: true}, oeMap, kuMap, fsMap)
+	assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, oeMap["oe5"].Errors, t)
+
+	// the rings in L or F only are not reported again as mixed cycles
+	if len(result.Cycles) != 3 || result.Cycles[2].Hierarchy != HierarchyMixed {
+		t.Fatalf("wanted the mixed cycle of oe5 and oe6 only, got: %d cycles", len(result.Cycles))
+	}
+	for _, id := range []string{"oe1", "oe2", "oe3", "oe4"} {
+		for _, e := range oeMap[id].Errors {
+			if e.Type == CycleError || e.Type == CycleLeadIn {
+				t.Errorf("wanted no mixed cycle finding for %s, got: %s", id, e.Message)
+			}
+		}
+	}
+}
+
+func TestCyclesOEMixedMembers(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1"}}
+	oeMap := make(map[string]*OEItem)
+	// a and b form a cycle in L, and b and c one following F up from b and L
+	// back down from c, whose shortest ring through b lies in L only
+	oeMap["a"] = &OEItem{Id: "a", ParentLId: "b", KUId: "ku1", FSId: "fs1"}
+	oeMap["b"] = &OEItem{Id: "b", ParentLId: "a", ParentFId: "c", KUId: "ku1", FSId: "fs1"}
+	oeMap["c"] = &OEItem{Id: "c", ParentLId: "b", KUId: "ku1", FSId: "fs1"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTreesWith(Options{MixedOECycles: true}, oeMap, kuMap, fsMap)
+
+	var mixed []string
+	for _, c := range result.Cycles {
+		if c.Hierarchy == HierarchyMixed {
+			mixed = append(mixed, c.String())
+		}
+	}
+	if len(mixed) != 1 || mixed[0] != "b -> c -> b" {
+		t.Errorf("wanted the mixed cycle b -> c -> b, got: %v", mixed)
+	}
+
+	assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, oeMap["b"].Errors, t)
+	assertError(Error{Message: CYCLE_REFERENCE, Type: CycleError}, oeMap["c"].Errors, t)
+	assertError(Error{Message: LEADS_INTO_CYCLE, Type: CycleLeadIn}, oeMap["a"].Errors, t)
+	assertError(Error{Message: CYCLE_REFERENCE_L, Type: CycleL}, oeMap["b"].Errors, t)
+}
+
+// generateKUChain returns count KU items, each the parent of the next one,
+// with the first two referencing each other.
+func generateKUChain(count int) map[string]*KUItem {
+	kuMap := make(map[string]*KUItem, count)
+	for i := 0; i < count; i++ {
+		kuMap[fmt.Sprintf("ku%d", i)] = &KUItem{Id: fmt.Sprintf("ku%d", i), ParentId: fmt.Sprintf("ku%d", i-1)}
+	}
+	kuMap["ku0"].ParentId = "ku1"
+	return kuMap
+}
+
+// generateOETree returns count OE items with random L and F parents among
+// the items generated before them.
+func generateOETree(count int) map[string]*OEItem {
+	random := rand.New(rand.NewSource(1))
+	oeMap := make(map[string]*OEItem, count)
+	oeMap["oe0"] = &OEItem{Id: "oe0"}
+	for i := 1; i < count; i++ {
+		oeMap[fmt.Sprintf("oe%d", i)] = &OEItem{
+			Id:        fmt.Sprintf("oe%d", i),
+			ParentLId: fmt.Sprintf("oe%d", random.Intn(i)),
+			ParentFId: fmt.Sprintf("oe%d", random.Intn(i)),
+		}
+	}
+	return oeMap
+}
+
+// walkCycleKU is the former per item cycle check, kept as a baseline for the
+// benchmarks.
+func walkCycleKU(item *KUItem, visited []*string) bool {
+	if item == nil || item.Parent == nil {
+		return false
+	}
+
+	for _, v := range visited {
+		if *v == item.Id {
+			return true
+		}
+	}
+
+	visited = append(visited, &item.Id)
+	return walkCycleKU(item.Parent, visited)
+}
+
+func BenchmarkCyclesKUChainPerItemWalk2k(b *testing.B) {
+	kuMap := generateKUChain(2000)
+	buildTrees(map[string]*OEItem{}, kuMap, map[string]*FSItem{})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range kuMap {
+			walkCycleKU(v, []*string{})
+		}
+	}
+}
+
+func BenchmarkCyclesKUChain2k(b *testing.B) {
+	kuMap := generateKUChain(2000)
+	buildTrees(map[string]*OEItem{}, kuMap, map[string]*FSItem{})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for _, v := range kuMap {
+			v.Errors = nil
+		}
+		b.StartTimer()
+
+		analyzeCyclesKU(kuMap, &Errors{})
+	}
+}
+
+// BenchmarkCyclesOETree200k only shows that the check stays usable for large
+// exports. The per item walk takes time cubic in the length of a chain, so
+// it is only compared with at 2k items.
+func BenchmarkCyclesOETree200k(b *testing.B) {
+	oeMap := generateOETree(200000)
+	buildTrees(oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for _, v := range oeMap {
+			v.Errors = nil
+		}
+		b.StartTimer()
+
+		analyzeCyclesOE(oeMap, Options{MixedOECycles: true}, &Errors{})
+	}
+}
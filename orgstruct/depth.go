@@ -0,0 +1,130 @@
+package orgstruct
+
+import (
+	"fmt"
+)
+
+const (
+	DEPTH_MISMATCH = "DEPTH differs from position in tree"
+	ROOT_DEPTH     = "root with non-zero DEPTH"
+)
+
+func addDepthMismatchError(item *ItemWithError, message string, detail string) *Error {
+	e := &Error{Message: message, Type: DepthMismatch, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+// depthsFS computes the depth of each FS item from its parents, with roots at
+// depth 0. Items with a dangling parent reference above them or in or below
+// a cycle have no known depth and are left out.
+func depthsFS(fsMap map[string]*FSItem) map[*FSItem]int {
+	depths := make(map[*FSItem]int, len(fsMap))
+	unknown := make(map[*FSItem]bool)
+
+	for _, start := range fsMap {
+		var path []*FSItem
+		onPath := make(map[*FSItem]bool)
+		depth := -1
+
+		for item := start; ; item = item.Parent {
+			if d, ok := depths[item]; ok {
+				depth = d
+				break
+			}
+			if unknown[item] || onPath[item] {
+				break
+			}
+			if item.Parent == nil {
+				if item.ParentId == "" {
+					depth = 0
+					depths[item] = 0
+				} else {
+					unknown[item] = true
+				}
+				break
+			}
+			path = append(path, item)
+			onPath[item] = true
+		}
+
+		for i := len(path) - 1; i >= 0; i-- {
+			if depth < 0 {
+				unknown[path[i]] = true
+			} else {
+				depth++
+				depths[path[i]] = depth
+			}
+		}
+	}
+
+	return depths
+}
+
+// depthsKU computes the depth of each KU item from its parents, with roots at
+// depth 0. Items with a dangling parent reference above them or in or below
+// a cycle have no known depth and are left out.
+func depthsKU(kuMap map[string]*KUItem) map[*KUItem]int {
+	depths := make(map[*KUItem]int, len(kuMap))
+	unknown := make(map[*KUItem]bool)
+
+	for _, start := range kuMap {
+		var path []*KUItem
+		onPath := make(map[*KUItem]bool)
+		depth := -1
+
+		for item := start; ; item = item.Parent {
+			if d, ok := depths[item]; ok {
+				depth = d
+				break
+			}
+			if unknown[item] || onPath[item] {
+				break
+			}
+			if item.Parent == nil {
+				if item.ParentId == "" {
+					depth = 0
+					depths[item] = 0
+				} else {
+					unknown[item] = true
+				}
+				break
+			}
+			path = append(path, item)
+			onPath[item] = true
+		}
+
+		for i := len(path) - 1; i >= 0; i-- {
+			if depth < 0 {
+				unknown[path[i]] = true
+			} else {
+				depth++
+				depths[path[i]] = depth
+			}
+		}
+	}
+
+	return depths
+}
+
+func analyzeDepthFS(fsMap map[string]*FSItem, result *Errors) {
+	depths := depthsFS(fsMap)
+	for _, v := range fsMap {
+		if v.ParentId == "" && v.Depth != 0 {
+			addFSError(v, addDepthMismatchError(&v.ItemWithError, ROOT_DEPTH, fmt.Sprintf("DEPTH %d", v.Depth)), result)
+		} else if depth, ok := depths[v]; ok && depth != v.Depth {
+			addFSError(v, addDepthMismatchError(&v.ItemWithError, DEPTH_MISMATCH, fmt.Sprintf("DEPTH %d, actual depth %d", v.Depth, depth)), result)
+		}
+	}
+}
+
+func analyzeDepthKU(kuMap map[string]*KUItem, result *Errors) {
+	depths := depthsKU(kuMap)
+	for _, v := range kuMap {
+		if v.ParentId == "" && v.Depth != 0 {
+			addKUError(v, addDepthMismatchError(&v.ItemWithError, ROOT_DEPTH, fmt.Sprintf("DEPTH %d", v.Depth)), result)
+		} else if depth, ok := depths[v]; ok && depth != v.Depth {
+			addKUError(v, addDepthMismatchError(&v.ItemWithError, DEPTH_MISMATCH, fmt.Sprintf("DEPTH %d, actual depth %d", v.Depth, depth)), result)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestKUDepth(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuMap["ku1"] = &KUItem{Id: "ku1", Depth: 0}
+	kuMap["ku2"] = &KUItem{Id: "ku2", ParentId: "ku1", Depth: 1}
+	kuMap["ku3"] = &KUItem{Id: "ku3", ParentId: "ku2", Depth: 1}
+	kuMap["ku4"] = &KUItem{Id: "ku4", ParentId: "ku3", Depth: 3}
+	kuMap["ku5"] = &KUItem{Id: "ku5", Depth: 2}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(0, kuMap["ku1"].Errors, t)
+	assertErrorCount(0, kuMap["ku2"].Errors, t)
+	assertErrorCount(1, kuMap["ku3"].Errors, t)
+	assertError(Error{Message: DEPTH_MISMATCH, Type: DepthMismatch}, kuMap["ku3"].Errors, t)
+	assertErrorCount(0, kuMap["ku4"].Errors, t)
+	assertErrorCount(1, kuMap["ku5"].Errors, t)
+	assertError(Error{Message: ROOT_DEPTH, Type: DepthMismatch}, kuMap["ku5"].Errors, t)
+
+	if kuMap["ku3"].Errors[0].Detail != "DEPTH 1, actual depth 2" {
+		t.Errorf("unexpected detail: %s", kuMap["ku3"].Errors[0].Detail)
+	}
+}
+
+func TestFSDepthUnknown(t *testing.T) {
+	fsMap := make(map[string]*FSItem)
+
+	// fs1 and fs2 form a cycle with fs3 below it, fs4 has a dangling parent
+	fsMap["fs1"] = &FSItem{Id: "fs1", ParentId: "fs2", Depth: 7}
+	fsMap["fs2"] = &FSItem{Id: "fs2", ParentId: "fs1", Depth: 7}
+	fsMap["fs3"] = &FSItem{Id: "fs3", ParentId: "fs1", Depth: 7}
+	fsMap["fs4"] = &FSItem{Id: "fs4", ParentId: "fs10", Depth: 7}
+	fsMap["fs5"] = &FSItem{Id: "fs5", ParentId: "fs4", Depth: 7}
+
+	buildTrees(map[string]*OEItem{}, map[string]*KUItem{}, fsMap)
+	depths := depthsFS(fsMap)
+
+	if len(depths) != 0 {
+		t.Errorf("wanted no known depths, got: %d", len(depths))
+	}
+
+	result := &Errors{}
+	analyzeDepthFS(fsMap, result)
+	if result.Count() != 0 {
+		t.Errorf("wanted no depth errors, got: %d", result.Count())
+	}
+}
@@ -0,0 +1,232 @@
+package orgstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Kinds of changes between two snapshots.
+const (
+	ChangeAdded      = "added"
+	ChangeRemoved    = "removed"
+	ChangeModified   = "changed"
+	ChangeReparented = "reparented"
+)
+
+// Change is a difference of one item between two snapshots. Attribute, Old
+// and New are only set for changed and reparented items and use the names
+// and formats of the export.
+type Change struct {
+	Kind      string `json:"kind"`
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Change    string `json:"change"`
+	Attribute string `json:"attribute,omitempty"`
+	Old       string `json:"old,omitempty"`
+	New       string `json:"new,omitempty"`
+}
+
+func (c *Change) String() string {
+	s := fmt.Sprintf("%s %s (%s) %s", c.Kind, c.Id, c.Name, c.Change)
+	if c.Attribute != "" {
+		s += fmt.Sprintf(" %s: %q -> %q", c.Attribute, c.Old, c.New)
+	}
+	return s
+}
+
+// DiffTotals counts the items per change. An item with several changed
+// attributes is counted once.
+type DiffTotals struct {
+	Added      int `json:"added"`
+	Removed    int `json:"removed"`
+	Changed    int `json:"changed"`
+	Reparented int `json:"reparented"`
+}
+
+// Diff lists the changes between two snapshots ordered by kind, id, change
+// and attribute.
+type Diff struct {
+	Changes []*Change  `json:"changes"`
+	Totals  DiffTotals `json:"totals"`
+
+	// counted holds the kind, id and change of the items already counted.
+	counted map[string]bool
+}
+
+// attributeValue is the value of an attribute compared by diffSnapshots.
+// References to other items are reported as re-parenting.
+type attributeValue struct {
+	name      string
+	value     string
+	reference bool
+}
+
+func fsAttributes(item *FSItem) []attributeValue {
+	return []attributeValue{
+		{name: "s_NODE_PARENT_ID", value: item.ParentId, reference: true},
+		{name: "FS_KURZ", value: item.NameShort},
+		{name: "FSLANG", value: item.NameLong},
+		{name: "DEPTH", value: strconv.Itoa(item.Depth)},
+		{name: "GAB", value: formatDate(item.From.Time)},
+		{name: "GBIS", value: formatDate(item.Until.Time)},
+	}
+}
+
+func kuAttributes(item *KUItem) []attributeValue {
+	return []attributeValue{
+		{name: "s_NODE_PARENT_ID", value: item.ParentId, reference: true},
+		{name: "KULANG", value: item.NameLong},
+		{name: "DEPTH", value: strconv.Itoa(item.Depth)},
+		{name: "GAB", value: formatDate(item.From.Time)},
+		{name: "GBIS", value: formatDate(item.Until.Time)},
+	}
+}
+
+func oeAttributes(item *OEItem) []attributeValue {
+	return []attributeValue{
+		{name: "s_PARENTOE_L_ID", value: item.ParentLId, reference: true},
+		{name: "s_PARENTOE_F_ID", value: item.ParentFId, reference: true},
+		{name: "s_KU_ID", value: item.KUId, reference: true},
+		{name: "s_FS_ID", value: item.FSId, reference: true},
+		{name: "Typ", value: item.Type},
+		{name: "Konzernunternehmen", value: item.KUName},
+		{name: "Führungsstruktur", value: item.FSName},
+		{name: "Org-Kz", value: item.OrgKZ},
+		{name: "Org-Bez1", value: item.OrgName1},
+		{name: "Org-Bez2", value: item.OrgName2},
+		{name: "Org-Bez3", value: item.OrgName3},
+		{name: "Standort", value: item.Location},
+		{name: "Firmierung1", value: item.CompanyName1},
+		{name: "Firmierung2", value: item.CompanyName2},
+		{name: "Gültig_x0020_ab", value: formatDate(item.From.Time)},
+		{name: "Gültig_x0020_bis", value: formatDate(item.Until.Time)},
+	}
+}
+
+func (d *Diff) add(c *Change) {
+	d.Changes = append(d.Changes, c)
+
+	if d.counted == nil {
+		d.counted = make(map[string]bool)
+	}
+	key := c.Kind + ":" + c.Id + ":" + c.Change
+	if d.counted[key] {
+		return
+	}
+	d.counted[key] = true
+
+	switch c.Change {
+	case ChangeAdded:
+		d.Totals.Added++
+	case ChangeRemoved:
+		d.Totals.Removed++
+	case ChangeModified:
+		d.Totals.Changed++
+	case ChangeReparented:
+		d.Totals.Reparented++
+	}
+}
+
+func (d *Diff) compare(kind string, id string, name string, old []attributeValue, next []attributeValue) {
+	for i := range old {
+		if old[i].value == next[i].value {
+			continue
+		}
+
+		change := ChangeModified
+		if old[i].reference {
+			change = ChangeReparented
+		}
+		d.add(&Change{Kind: kind, Id: id, Name: name, Change: change, Attribute: old[i].name, Old: old[i].value, New: next[i].value})
+	}
+}
+
+// diffSnapshots compares the items of two snapshots, e.g. the latest
+// versions of yesterday's and today's export.
+func diffSnapshots(
+	oldOE map[string]*OEItem, oldKU map[string]*KUItem, oldFS map[string]*FSItem,
+	newOE map[string]*OEItem, newKU map[string]*KUItem, newFS map[string]*FSItem) *Diff {
+	d := &Diff{Changes: []*Change{}}
+
+	for id, o := range oldFS {
+		if n, ok := newFS[id]; ok {
+			d.compare(KindFS, id, n.NameLong, fsAttributes(o), fsAttributes(n))
+		} else {
+			d.add(&Change{Kind: KindFS, Id: id, Name: o.NameLong, Change: ChangeRemoved})
+		}
+	}
+	for id, n := range newFS {
+		if _, ok := oldFS[id]; !ok {
+			d.add(&Change{Kind: KindFS, Id: id, Name: n.NameLong, Change: ChangeAdded})
+		}
+	}
+
+	for id, o := range oldKU {
+		if n, ok := newKU[id]; ok {
+			d.compare(KindKU, id, n.NameLong, kuAttributes(o), kuAttributes(n))
+		} else {
+			d.add(&Change{Kind: KindKU, Id: id, Name: o.NameLong, Change: ChangeRemoved})
+		}
+	}
+	for id, n := range newKU {
+		if _, ok := oldKU[id]; !ok {
+			d.add(&Change{Kind: KindKU, Id: id, Name: n.NameLong, Change: ChangeAdded})
+		}
+	}
+
+	for id, o := range oldOE {
+		if n, ok := newOE[id]; ok {
+			d.compare(KindOE, id, n.OrgKZ, oeAttributes(o), oeAttributes(n))
+		} else {
+			d.add(&Change{Kind: KindOE, Id: id, Name: o.OrgKZ, Change: ChangeRemoved})
+		}
+	}
+	for id, n := range newOE {
+		if _, ok := oldOE[id]; !ok {
+			d.add(&Change{Kind: KindOE, Id: id, Name: n.OrgKZ, Change: ChangeAdded})
+		}
+	}
+
+	d.sort()
+	return d
+}
+
+var changeOrder = map[string]int{ChangeRemoved: 0, ChangeAdded: 1, ChangeReparented: 2, ChangeModified: 3}
+
+func (d *Diff) sort() {
+	sort.SliceStable(d.Changes, func(i, j int) bool {
+		a, b := d.Changes[i], d.Changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Id != b.Id {
+			return a.Id < b.Id
+		}
+		if a.Change != b.Change {
+			return changeOrder[a.Change] < changeOrder[b.Change]
+		}
+		return a.Attribute < b.Attribute
+	})
+}
+
+// WriteTextDiff lists the changes, one per line, followed by the totals.
+func WriteTextDiff(w io.Writer, d *Diff) error {
+	for _, c := range d.Changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d added, %d removed, %d changed, %d reparented\n",
+		d.Totals.Added, d.Totals.Removed, d.Totals.Changed, d.Totals.Reparented)
+	return err
+}
+
+// WriteJSONDiff writes the changes and totals as indented JSON.
+func WriteJSONDiff(w io.Writer, d *Diff) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
@@ -0,0 +1,65 @@
+package orgstruct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	oldKU := map[string]*KUItem{"ku1": {Id: "ku1", NameLong: "Konzern"}, "ku2": {Id: "ku2", NameLong: "Alt"}}
+	newKU := map[string]*KUItem{"ku1": {Id: "ku1", NameLong: "Konzern AG"}, "ku3": {Id: "ku3", NameLong: "Neu"}}
+	oldOE := map[string]*OEItem{"oe1": {Id: "oe1", OrgKZ: "A", ParentLId: "oe2", Location: "Berlin", From: parseTime("2019-01-01T00:00:00")}}
+	newOE := map[string]*OEItem{"oe1": {Id: "oe1", OrgKZ: "B", ParentLId: "oe3", Location: "Berlin", From: parseTime("2020-01-01T00:00:00")}}
+	fs := map[string]*FSItem{"fs1": {Id: "fs1"}}
+
+	d := diffSnapshots(oldOE, oldKU, fs, newOE, newKU, fs)
+
+	expected := []Change{
+		{Kind: KindKU, Id: "ku1", Name: "Konzern AG", Change: ChangeModified, Attribute: "KULANG", Old: "Konzern", New: "Konzern AG"},
+		{Kind: KindKU, Id: "ku2", Name: "Alt", Change: ChangeRemoved},
+		{Kind: KindKU, Id: "ku3", Name: "Neu", Change: ChangeAdded},
+		{Kind: KindOE, Id: "oe1", Name: "B", Change: ChangeReparented, Attribute: "s_PARENTOE_L_ID", Old: "oe2", New: "oe3"},
+		{Kind: KindOE, Id: "oe1", Name: "B", Change: ChangeModified, Attribute: "Gültig_x0020_ab", Old: "2019-01-01", New: "2020-01-01"},
+		{Kind: KindOE, Id: "oe1", Name: "B", Change: ChangeModified, Attribute: "Org-Kz", Old: "A", New: "B"},
+	}
+
+	if len(d.Changes) != len(expected) {
+		t.Fatalf("wanted %d changes, got: %d", len(expected), len(d.Changes))
+	}
+
+	for i, c := range d.Changes {
+		if *c != expected[i] {
+			t.Errorf("wanted %s, got: %s", &expected[i], c)
+		}
+	}
+
+	if d.Totals != (DiffTotals{Added: 1, Removed: 1, Changed: 2, Reparented: 1}) {
+		t.Errorf("unexpected totals: %+v", d.Totals)
+	}
+}
+
+func TestWriteTextDiff(t *testing.T) {
+	d := diffSnapshots(
+		map[string]*OEItem{}, map[string]*KUItem{}, map[string]*FSItem{},
+		map[string]*OEItem{"oe1": {Id: "oe1", OrgKZ: "A"}}, map[string]*KUItem{}, map[string]*FSItem{})
+
+	var buffer bytes.Buffer
+	if err := WriteTextDiff(&buffer, d); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	expected := "OE oe1 (A) added\n1 added, 0 removed, 0 changed, 0 reparented\n"
+	if buffer.String() != expected {
+		t.Errorf("wanted %q, got: %q", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	if err := WriteJSONDiff(&buffer, d); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	if !strings.Contains(buffer.String(), `"change": "added"`) {
+		t.Errorf("unexpected JSON: %s", buffer.String())
+	}
+}
@@ -0,0 +1,329 @@
+package orgstruct
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportOptions configures the exports of the linked hierarchies.
+type ExportOptions struct {
+	// Root is the id of the item to limit the export to. It is looked up in
+	// Hierarchy, and the export covers its subtree together with the items
+	// linked to it. Everything is exported if it is empty.
+	Root string
+	// Hierarchy is one of TreeKU, TreeFS, TreeOEL or TreeOEF.
+	Hierarchy string
+}
+
+// selection is the set of items to export.
+type selection struct {
+	oe map[*OEItem]bool
+	ku map[*KUItem]bool
+	fs map[*FSItem]bool
+}
+
+func collectKU(item *KUItem, s *selection) {
+	if s.ku[item] {
+		return
+	}
+	s.ku[item] = true
+	for _, child := range item.Children {
+		collectKU(child, s)
+	}
+}
+
+func collectFS(item *FSItem, s *selection) {
+	if s.fs[item] {
+		return
+	}
+	s.fs[item] = true
+	for _, child := range item.Children {
+		collectFS(child, s)
+	}
+}
+
+func collectOEL(item *OEItem, s *selection) {
+	if s.oe[item] {
+		return
+	}
+	s.oe[item] = true
+	for _, child := range item.LChildren {
+		collectOEL(child, s)
+	}
+}
+
+func collectOEF(item *OEItem, s *selection) {
+	if s.oe[item] {
+		return
+	}
+	s.oe[item] = true
+	for _, child := range item.FChildren {
+		collectOEF(child, s)
+	}
+}
+
+// selectItems picks the subtree of the root and the items linked to it: the
+// OE of a KU or FS subtree and the KU and FS of the selected OE.
+func selectItems(options ExportOptions, oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) (*selection, error) {
+	s := &selection{oe: make(map[*OEItem]bool), ku: make(map[*KUItem]bool), fs: make(map[*FSItem]bool)}
+
+	if options.Root == "" {
+		for _, item := range oeMap {
+			s.oe[item] = true
+		}
+		for _, item := range kuMap {
+			s.ku[item] = true
+		}
+		for _, item := range fsMap {
+			s.fs[item] = true
+		}
+		return s, nil
+	}
+
+	var found bool
+	switch options.Hierarchy {
+	case TreeKU:
+		if item, ok := kuMap[options.Root]; ok {
+			found = true
+			collectKU(item, s)
+			for _, oe := range oeMap {
+				if s.ku[oe.KU] {
+					s.oe[oe] = true
+				}
+			}
+		}
+	case TreeFS:
+		if item, ok := fsMap[options.Root]; ok {
+			found = true
+			collectFS(item, s)
+			for _, oe := range oeMap {
+				if s.fs[oe.FS] {
+					s.oe[oe] = true
+				}
+			}
+		}
+	case TreeOEL:
+		if item, ok := oeMap[options.Root]; ok {
+			found = true
+			collectOEL(item, s)
+		}
+	case TreeOEF:
+		if item, ok := oeMap[options.Root]; ok {
+			found = true
+			collectOEF(item, s)
+		}
+	default:
+		return nil, fmt.Errorf("unknown hierarchy: %s", options.Hierarchy)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown start node: %s", options.Root)
+	}
+
+	for oe := range s.oe {
+		if oe.KU != nil {
+			s.ku[oe.KU] = true
+		}
+		if oe.FS != nil {
+			s.fs[oe.FS] = true
+		}
+	}
+	return s, nil
+}
+
+// cycleEdges returns the parent edges that are part of a cycle, keyed by
+// kind, hierarchy, child id and parent id.
+func cycleEdges(errors *Errors) map[string]bool {
+	edges := make(map[string]bool)
+	for _, c := range errors.Cycles {
+		for i, id := range c.Ids {
+			parent := c.Ids[(i+1)%len(c.Ids)]
+			switch c.Hierarchy {
+			case HierarchyMixed:
+				edges[edgeKey(c.Kind, HierarchyL, id, parent)] = true
+				edges[edgeKey(c.Kind, HierarchyF, id, parent)] = true
+			default:
+				edges[edgeKey(c.Kind, c.Hierarchy, id, parent)] = true
+			}
+		}
+	}
+	return edges
+}
+
+func edgeKey(kind string, hierarchy string, child string, parent string) string {
+	return kind + "/" + hierarchy + "/" + child + "/" + parent
+}
+
+func dotId(kind string, id string) string {
+	return strconv.Quote(kind + ":" + id)
+}
+
+func missingDotId(kind string, id string) string {
+	return strconv.Quote("missing:" + kind + ":" + id)
+}
+
+// dotWriter writes the statements of a DOT graph. Write errors are returned
+// by Flush.
+type dotWriter struct {
+	*bufio.Writer
+	missing map[string]bool
+	cycles  map[string]bool
+}
+
+func (d *dotWriter) node(kind string, id string, label string, itemErrors []*Error) {
+	attributes := fmt.Sprintf("label=%s", strconv.Quote(label))
+	if len(itemErrors) > 0 {
+		messages := make([]string, len(itemErrors))
+		severity := SeverityInfo
+		for i, e := range itemErrors {
+			messages[i] = e.Message
+			if e.Severity > severity {
+				severity = e.Severity
+			}
+		}
+		color := "red"
+		if severity < SeverityError {
+			color = "orange"
+		}
+		attributes += fmt.Sprintf(", color=%s, penwidth=2, tooltip=%s", color, strconv.Quote(strings.Join(messages, "\n")))
+	}
+	fmt.Fprintf(d, "    %s [%s];\n", dotId(kind, id), attributes)
+}
+
+// reference writes the edge from an item to the item it references. If the
+// reference does not exist, a red edge points at a placeholder node instead.
+// Parent references keep pointing from parent to child and keep the style of
+// their hierarchy, other references are dashed.
+func (d *dotWriter) reference(from string, kind string, id string, exists bool, parent bool, style string) {
+	if exists {
+		fmt.Fprintf(d, "  %s -> %s [%s];\n", from, dotId(kind, id), style)
+		return
+	}
+
+	to := missingDotId(kind, id)
+	if !d.missing[to] {
+		d.missing[to] = true
+		fmt.Fprintf(d, "  %s [label=%s, style=dashed, color=red, fontcolor=red];\n", to, strconv.Quote(id+"\n(missing "+kind+")"))
+	}
+	if parent {
+		fmt.Fprintf(d, "  %s -> %s [%s];\n", to, from, strings.TrimPrefix(style+", color=red", ", "))
+	} else {
+		fmt.Fprintf(d, "  %s -> %s [style=dashed, color=red];\n", from, to)
+	}
+}
+
+// parent writes the edge from a parent to a child, highlighting it if it is
+// part of a cycle.
+func (d *dotWriter) parent(kind string, hierarchy string, child string, parent string, style string) {
+	if d.cycles[edgeKey(kind, hierarchy, child, parent)] {
+		style = strings.TrimPrefix(style+", color=red, penwidth=2", ", ")
+	}
+	fmt.Fprintf(d, "  %s -> %s [%s];\n", dotId(kind, parent), dotId(kind, child), style)
+}
+
+// WriteDOT writes the KU, FS, OE-L and OE-F hierarchies and the links of OE
+// to KU and FS as a GraphViz graph. Items with errors, references to
+// non-existing items and edges of cycles are highlighted.
+func WriteDOT(w io.Writer, options ExportOptions, oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem, errors *Errors) error {
+	s, err := selectItems(options, oeMap, kuMap, fsMap)
+	if err != nil {
+		return err
+	}
+
+	var kuItems []*KUItem
+	for item := range s.ku {
+		kuItems = append(kuItems, item)
+	}
+	sort.Slice(kuItems, func(i, j int) bool { return kuItems[i].Id < kuItems[j].Id })
+
+	var fsItems []*FSItem
+	for item := range s.fs {
+		fsItems = append(fsItems, item)
+	}
+	sort.Slice(fsItems, func(i, j int) bool { return fsItems[i].Id < fsItems[j].Id })
+
+	var oeItems []*OEItem
+	for item := range s.oe {
+		oeItems = append(oeItems, item)
+	}
+	sort.Slice(oeItems, func(i, j int) bool { return oeItems[i].Id < oeItems[j].Id })
+
+	d := &dotWriter{Writer: bufio.NewWriter(w), missing: make(map[string]bool), cycles: cycleEdges(errors)}
+	fmt.Fprintln(d, "digraph structure {")
+	fmt.Fprintln(d, "  rankdir=LR;")
+	fmt.Fprintln(d, "  node [shape=box];")
+
+	fmt.Fprintln(d, "  subgraph cluster_KU {")
+	fmt.Fprintln(d, "    label=\"KU\";")
+	for _, item := range kuItems {
+		d.node(KindKU, item.Id, item.NameLong+"\n"+item.Id, item.Errors)
+	}
+	fmt.Fprintln(d, "  }")
+
+	fmt.Fprintln(d, "  subgraph cluster_FS {")
+	fmt.Fprintln(d, "    label=\"FS\";")
+	for _, item := range fsItems {
+		d.node(KindFS, item.Id, item.NameLong+"\n"+item.Id, item.Errors)
+	}
+	fmt.Fprintln(d, "  }")
+
+	fmt.Fprintln(d, "  subgraph cluster_OE {")
+	fmt.Fprintln(d, "    label=\"OE\";")
+	for _, item := range oeItems {
+		d.node(KindOE, item.Id, oeLabel(item), item.Errors)
+	}
+	fmt.Fprintln(d, "  }")
+
+	for _, item := range kuItems {
+		if item.ParentId == "" {
+			continue
+		}
+		if item.Parent == nil {
+			d.reference(dotId(KindKU, item.Id), KindKU, item.ParentId, false, true, "")
+		} else if s.ku[item.Parent] {
+			d.parent(KindKU, "", item.Id, item.ParentId, "")
+		}
+	}
+
+	for _, item := range fsItems {
+		if item.ParentId == "" {
+			continue
+		}
+		if item.Parent == nil {
+			d.reference(dotId(KindFS, item.Id), KindFS, item.ParentId, false, true, "")
+		} else if s.fs[item.Parent] {
+			d.parent(KindFS, "", item.Id, item.ParentId, "")
+		}
+	}
+
+	for _, item := range oeItems {
+		from := dotId(KindOE, item.Id)
+		if item.ParentLId != "" {
+			if item.ParentL == nil {
+				d.reference(from, KindOE, item.ParentLId, false, true, "")
+			} else if s.oe[item.ParentL] {
+				d.parent(KindOE, HierarchyL, item.Id, item.ParentLId, "")
+			}
+		}
+		if item.ParentFId != "" {
+			if item.ParentF == nil {
+				d.reference(from, KindOE, item.ParentFId, false, true, "style=dashed")
+			} else if s.oe[item.ParentF] {
+				d.parent(KindOE, HierarchyF, item.Id, item.ParentFId, "style=dashed")
+			}
+		}
+		if item.KUId != "" {
+			d.reference(from, KindKU, item.KUId, item.KU != nil, false, "style=dotted, color=gray")
+		}
+		if item.FSId != "" {
+			d.reference(from, KindFS, item.FSId, item.FS != nil, false, "style=dotted, color=blue")
+		}
+	}
+
+	fmt.Fprintln(d, "}")
+	return d.Flush()
+}
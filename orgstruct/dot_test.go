@@ -0,0 +1,111 @@
+package orgstruct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCycleEdges(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1", ParentId: "ku2"},
+		"ku2": {Id: "ku2", ParentId: "ku3"},
+		"ku3": {Id: "ku3", ParentId: "ku1"},
+	}
+	oeMap := make(map[string]*OEItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+	edges := cycleEdges(analyzeTrees(oeMap, kuMap, fsMap))
+
+	for _, e := range [][2]string{{"ku1", "ku2"}, {"ku2", "ku3"}, {"ku3", "ku1"}} {
+		if !edges[edgeKey(KindKU, "", e[0], e[1])] {
+			t.Errorf("wanted cycle edge from %s to %s", e[0], e[1])
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1", NameLong: "Konzern"},
+		"ku2": {Id: "ku2", ParentId: "ku1", NameLong: "Tochter", Depth: 1},
+	}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1", NameLong: "Vorstand"}}
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", KUId: "ku2", FSId: "fs1", OrgKZ: "A"},
+		"oe2": {Id: "oe2", KUId: "ku2", FSId: "fs9", ParentLId: "oe1", ParentFId: "oe1", OrgKZ: "B"},
+		"oe3": {Id: "oe3", KUId: "ku1", FSId: "fs1", OrgKZ: "C"},
+	}
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := WriteDOT(&buffer, ExportOptions{Root: "ku2", Hierarchy: TreeKU}, oeMap, kuMap, fsMap, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+	dot := buffer.String()
+
+	for _, expected := range []string{
+		`"KU:ku2" [label="Tochter\nku2"];`,
+		`"OE:oe1" -> "OE:oe2" [];`,
+		`"OE:oe1" -> "OE:oe2" [style=dashed];`,
+		`"OE:oe1" -> "KU:ku2" [style=dotted, color=gray];`,
+		`"missing:FS:fs9" [label="fs9\n(missing FS)", style=dashed, color=red, fontcolor=red];`,
+		`"OE:oe2" -> "missing:FS:fs9" [style=dashed, color=red];`,
+		`"OE:oe2" [label="B (oe2)", color=red`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("wanted %s in:\n%s", expected, dot)
+		}
+	}
+
+	for _, unexpected := range []string{`"KU:ku1"`, `"OE:oe3"`} {
+		if strings.Contains(dot, unexpected) {
+			t.Errorf("wanted no %s outside the subtree in:\n%s", unexpected, dot)
+		}
+	}
+}
+
+func TestWriteDOTCycle(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", ParentLId: "oe2"},
+		"oe2": {Id: "oe2", ParentLId: "oe1"},
+	}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := WriteDOT(&buffer, ExportOptions{}, oeMap, kuMap, fsMap, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	if !strings.Contains(buffer.String(), `"OE:oe2" -> "OE:oe1" [color=red, penwidth=2];`) {
+		t.Errorf("wanted highlighted cycle edge in:\n%s", buffer.String())
+	}
+}
+
+func TestWriteDOTMissingParents(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", ParentLId: "oe8", ParentFId: "oe9"},
+	}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := WriteDOT(&buffer, ExportOptions{}, oeMap, kuMap, fsMap, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+	dot := buffer.String()
+
+	for _, expected := range []string{
+		`"missing:OE:oe8" -> "OE:oe1" [color=red];`,
+		`"missing:OE:oe9" -> "OE:oe1" [style=dashed, color=red];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("wanted %s in:\n%s", expected, dot)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package orgstruct
+
+import (
+	"fmt"
+)
+
+const DUPLICATE_ID = "duplicate id"
+
+// addDuplicateIdErrors records a collision between a kept row and a dropped
+// row with the same id and validity on both of them. It returns true if this
+// is the first collision of the kept row.
+func addDuplicateIdErrors(kept *ItemWithError, keptLine int, dropped *ItemWithError, droppedLine int) bool {
+	dropped.Errors = append(dropped.Errors, &Error{
+		Message: DUPLICATE_ID,
+		Type:    DuplicateId,
+		Detail:  fmt.Sprintf("dropped row at line %d, kept row at line %d", droppedLine, keptLine),
+	})
+
+	for _, e := range kept.Errors {
+		if e.Type == DuplicateId {
+			e.Detail += fmt.Sprintf(", %d", droppedLine)
+			return false
+		}
+	}
+
+	kept.Errors = append(kept.Errors, &Error{
+		Message: DUPLICATE_ID,
+		Type:    DuplicateId,
+		Detail:  fmt.Sprintf("kept row at line %d, dropped rows at lines %d", keptLine, droppedLine),
+	})
+	return true
+}
+
+// reportDuplicates adds the errors raised for colliding rows while parsing to
+// the report.
+func reportDuplicates(oeDuplicates []*OEItem, kuDuplicates []*KUItem, fsDuplicates []*FSItem, result *Errors) {
+	for _, v := range kuDuplicates {
+		for _, e := range v.Errors {
+			if e.Type == DuplicateId {
+				addKUError(v, e, result)
+			}
+		}
+	}
+
+	for _, v := range fsDuplicates {
+		for _, e := range v.Errors {
+			if e.Type == DuplicateId {
+				addFSError(v, e, result)
+			}
+		}
+	}
+
+	for _, v := range oeDuplicates {
+		for _, e := range v.Errors {
+			if e.Type == DuplicateId {
+				addOEError(v, e, result)
+			}
+		}
+	}
+
+	result.sort()
+}
@@ -0,0 +1,81 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestParseFSDuplicates(t *testing.T) {
+	input := `<vw_FS>
+				<FS s_NODE_FS_ID="fs1" GAB="1900-01-01T00:00:00" GBIS="9999-12-31T00:00:00" FSLANG="first" />
+				<FS s_NODE_FS_ID="fs2" GAB="1900-01-01T00:00:00" GBIS="9999-12-31T00:00:00" FSLANG="other" />
+				<FS s_NODE_FS_ID="fs1" GAB="1900-01-01T00:00:00" GBIS="9999-12-31T00:00:00" FSLANG="second" />
+				<FS s_NODE_FS_ID="fs1" GAB="1900-01-01T00:00:00"
+					GBIS="9999-12-31T00:00:00" FSLANG="third" />
+			  </vw_FS>`
+
+	fsVersions, duplicates, err := parseFSBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(fsVersions["fs1"]) != 1 || fsVersions["fs1"][0].NameLong != "first" {
+		t.Fatalf("wanted only the first row of fs1 to be kept, got: %d versions", len(fsVersions["fs1"]))
+	}
+
+	if len(duplicates) != 3 {
+		t.Fatalf("wanted 3 duplicates, got: %d", len(duplicates))
+	}
+
+	expected := []struct {
+		name   string
+		line   int
+		detail string
+	}{
+		{"first", 2, "kept row at line 2, dropped rows at lines 4, 5"},
+		{"second", 4, "dropped row at line 4, kept row at line 2"},
+		{"third", 5, "dropped row at line 5, kept row at line 2"},
+	}
+
+	for i, e := range expected {
+		item := duplicates[i]
+		if item.NameLong != e.name || item.Line != e.line {
+			t.Errorf("wanted duplicate %s at line %d, got: %s at line %d", e.name, e.line, item.NameLong, item.Line)
+		}
+
+		assertErrorCount(1, item.Errors, t)
+		assertError(Error{Message: DUPLICATE_ID, Type: DuplicateId}, item.Errors, t)
+		if item.Errors[0].Detail != e.detail {
+			t.Errorf("wanted detail %q, got: %q", e.detail, item.Errors[0].Detail)
+		}
+	}
+
+	result := &Errors{}
+	reportDuplicates(nil, nil, duplicates, result)
+
+	findings := result.Findings()
+	if len(findings) != 3 {
+		t.Fatalf("wanted 3 findings, got: %d", len(findings))
+	}
+
+	for _, f := range findings {
+		if f.Kind != KindFS || f.Id != "fs1" || f.Type != DuplicateId || f.Line == 0 {
+			t.Errorf("unexpected finding: %+v", f)
+		}
+	}
+}
+
+func TestParseOEVersionsAreNoDuplicates(t *testing.T) {
+	input := `<OETBL>
+				<OE s_OE_ID="oe1" Gültig_x0020_ab="2002-07-18T00:00:00" Gültig_x0020_bis="2009-12-31T00:00:00" />
+				<OE s_OE_ID="oe1" Gültig_x0020_ab="2010-01-01T00:00:00" Gültig_x0020_bis="9999-12-31T00:00:00" />
+			  </OETBL>`
+
+	oeVersions, duplicates, err := parseOEBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(oeVersions["oe1"]) != 2 || len(duplicates) != 0 {
+		t.Errorf("wanted 2 versions and no duplicates, got: %d and %d", len(oeVersions["oe1"]), len(duplicates))
+	}
+}
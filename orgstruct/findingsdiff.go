@@ -0,0 +1,104 @@
+package orgstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// findingKey identifies a finding across two runs. Details and lines are left
+// out, as they change with unrelated edits of the exports.
+type findingKey struct {
+	kind      string
+	id        string
+	t         ErrorType
+	message   string
+	reference string
+}
+
+func keyOf(f *Finding) findingKey {
+	return findingKey{f.Kind, f.Id, f.Type, f.Message, f.Reference}
+}
+
+// FindingsDiff classifies the findings of two runs. New and Persisting hold
+// the findings of the new run, Resolved those of the old one.
+type FindingsDiff struct {
+	New        []*ReportFinding `json:"new"`
+	Resolved   []*ReportFinding `json:"resolved"`
+	Persisting []*ReportFinding `json:"persisting"`
+}
+
+// DiffFindings compares the errors of an old and a new run, keeping the order
+// of Findings.
+func DiffFindings(old *Errors, next *Errors) *FindingsDiff {
+	d := &FindingsDiff{New: []*ReportFinding{}, Resolved: []*ReportFinding{}, Persisting: []*ReportFinding{}}
+
+	oldKeys := make(map[findingKey]bool)
+	for _, f := range old.Findings() {
+		oldKeys[keyOf(f)] = true
+	}
+
+	newKeys := make(map[findingKey]bool)
+	for _, f := range next.Findings() {
+		newKeys[keyOf(f)] = true
+		if oldKeys[keyOf(f)] {
+			d.Persisting = append(d.Persisting, newReportFinding(f))
+		} else {
+			d.New = append(d.New, newReportFinding(f))
+		}
+	}
+
+	for _, f := range old.Findings() {
+		if !newKeys[keyOf(f)] {
+			d.Resolved = append(d.Resolved, newReportFinding(f))
+		}
+	}
+
+	return d
+}
+
+// Fails reports whether there is a new finding of at least the given
+// severity.
+func (d *FindingsDiff) Fails(threshold Severity) bool {
+	for _, f := range d.New {
+		if f.Severity >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTextFindingsDiff lists new and resolved findings; persisting ones are
+// only counted.
+func WriteTextFindingsDiff(w io.Writer, d *FindingsDiff) error {
+	groups := []struct {
+		name     string
+		findings []*ReportFinding
+	}{
+		{"new", d.New},
+		{"resolved", d.Resolved},
+	}
+
+	for _, g := range groups {
+		for _, f := range g.findings {
+			line := fmt.Sprintf("%s %s %s %s (%s): %s", g.name, f.Severity, f.Kind, f.Id, f.Name, f.Message)
+			if f.Detail != "" {
+				line += " (" + f.Detail + ")"
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d new, %d resolved, %d persisting\n", len(d.New), len(d.Resolved), len(d.Persisting))
+	return err
+}
+
+// WriteJSONFindingsDiff writes the new, resolved and persisting findings as
+// indented JSON.
+func WriteJSONFindingsDiff(w io.Writer, d *FindingsDiff) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
@@ -0,0 +1,58 @@
+package orgstruct
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiffFindings(t *testing.T) {
+	oldOE := map[string]*OEItem{
+		"oe1": {Id: "oe1", KUId: "ku1"},
+		"oe2": {Id: "oe2", KUId: "ku1", FSId: "fs1"},
+	}
+	newOE := map[string]*OEItem{
+		"oe1": {Id: "oe1", KUId: "ku1"},
+		"oe2": {Id: "oe2", KUId: "ku1", FSId: "fs1", ParentLId: "oe9"},
+	}
+	oldKU := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	newKU := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	oldFS := map[string]*FSItem{"fs1": {Id: "fs1", ParentId: "fs9"}}
+	newFS := map[string]*FSItem{"fs1": {Id: "fs1"}}
+
+	buildTrees(oldOE, oldKU, oldFS)
+	buildTrees(newOE, newKU, newFS)
+	d := DiffFindings(analyzeTrees(oldOE, oldKU, oldFS), analyzeTrees(newOE, newKU, newFS))
+
+	if len(d.New) != 1 || d.New[0].Id != "oe2" || d.New[0].Type != NonExistingReference {
+		t.Errorf("wanted the non-existing parent of oe2 as new, got: %d", len(d.New))
+	}
+
+	if len(d.Resolved) != 1 || d.Resolved[0].Id != "fs1" {
+		t.Errorf("wanted the non-existing parent of fs1 as resolved, got: %d", len(d.Resolved))
+	}
+
+	if len(d.Persisting) != 1 || d.Persisting[0].Id != "oe1" || d.Persisting[0].Message != NO_RELATED_FS_ID {
+		t.Errorf("wanted the missing FS of oe1 as persisting, got: %d", len(d.Persisting))
+	}
+
+	if !d.Fails(SeverityError) {
+		t.Errorf("wanted the new error to fail")
+	}
+}
+
+func TestWriteTextFindingsDiff(t *testing.T) {
+	d := &FindingsDiff{
+		New:        []*ReportFinding{{Kind: KindOE, Id: "oe1", Name: "A", Severity: SeverityError, Message: NO_RELATED_FS_ID}},
+		Persisting: []*ReportFinding{{Kind: KindOE, Id: "oe2"}},
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteTextFindingsDiff(&buffer, d); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	expected := "new error OE oe1 (A): no related FS id\n1 new, 0 resolved, 1 persisting\n"
+	if buffer.String() != expected {
+		t.Errorf("wanted %q, got: %q", expected, buffer.String())
+	}
+}
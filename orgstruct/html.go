@@ -0,0 +1,195 @@
+package orgstruct
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlNode is an OE of the org chart in the HTML report.
+type htmlNode struct {
+	Id       string
+	Label    string
+	State    string
+	Messages []string
+	Cycle    bool
+	Children []*htmlNode
+}
+
+// htmlFinding is a row of the findings table. Anchor is set for findings of
+// OE items, which are part of the org chart.
+type htmlFinding struct {
+	*Finding
+	Anchor string
+}
+
+type htmlReport struct {
+	Roots    []*htmlNode
+	Findings []*htmlFinding
+	Totals   reportTotals
+}
+
+func htmlAnchor(id string) string {
+	return "oe-" + id
+}
+
+func newHTMLNode(node *treeNode) *htmlNode {
+	n := &htmlNode{Id: node.id, Label: node.label, State: "ok", Cycle: node.cycle}
+	if !node.cycle {
+		severity := SeverityInfo
+		for _, e := range node.errors {
+			n.Messages = append(n.Messages, e.Message)
+			if e.Severity > severity {
+				severity = e.Severity
+			}
+		}
+		if len(node.errors) > 0 {
+			n.State = severity.String()
+		}
+	}
+
+	for _, child := range node.children {
+		n.Children = append(n.Children, newHTMLNode(child))
+	}
+	return n
+}
+
+// WriteHTMLReport writes the OE-L hierarchy as a collapsible, searchable org
+// chart followed by the findings. The page has no external dependencies.
+func WriteHTMLReport(w io.Writer, oeMap map[string]*OEItem, errors *Errors) error {
+	roots, err := treeRoots(TreeOEL, TreeOptions{Errors: true}, oeMap, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	r := &htmlReport{Totals: buildReport(errors).Totals}
+	for _, root := range roots {
+		r.Roots = append(r.Roots, newHTMLNode(root))
+	}
+	for _, f := range errors.Findings() {
+		h := &htmlFinding{Finding: f}
+		if f.Kind == KindOE && oeMap[f.Id] != nil {
+			h.Anchor = htmlAnchor(f.Id)
+		}
+		r.Findings = append(r.Findings, h)
+	}
+
+	return htmlTemplate.Execute(w, r)
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"anchor": htmlAnchor,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Organisational structure</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1, h2 { font-weight: normal; }
+#search { width: 30em; padding: 0.3em; margin-bottom: 1em; }
+ul.tree, ul.tree ul { list-style: none; padding-left: 1.5em; }
+ul.tree { padding-left: 0; }
+li.collapsed > ul { display: none; }
+li.hidden { display: none; }
+.toggle { display: inline-block; width: 1em; cursor: pointer; user-select: none; }
+li.collapsed > .toggle { transform: rotate(-90deg); }
+.node { padding: 0.1em 0.4em; border-radius: 3px; border-left: 4px solid #4caf50; }
+.node.error { border-color: #e53935; background: #fdecea; }
+.node.warning { border-color: #fb8c00; background: #fff4e5; }
+.node.info { border-color: #1e88e5; }
+.node.cycle { border-color: #e53935; font-style: italic; }
+.node.match { outline: 2px solid #fdd835; }
+.node.target { outline: 2px solid #222; }
+.messages { color: #666; font-size: 0.9em; margin-left: 0.5em; }
+table { border-collapse: collapse; margin-top: 1em; }
+th, td { text-align: left; padding: 0.2em 0.8em; border-bottom: 1px solid #ddd; }
+td.error { color: #e53935; }
+td.warning { color: #fb8c00; }
+</style>
+</head>
+<body>
+<h1>Organisational structure</h1>
+<p>{{.Totals.Error}} errors, {{.Totals.Warning}} warnings, {{.Totals.Info}} infos</p>
+<h2>OE line hierarchy</h2>
+<input id="search" type="search" placeholder="Search OrgKZ, name or id">
+<ul class="tree">
+{{range .Roots}}{{template "node" .}}{{end}}
+</ul>
+<h2>Findings</h2>
+<table>
+<tr><th>Severity</th><th>Kind</th><th>Id</th><th>Name</th><th>Message</th><th>Detail</th></tr>
+{{range .Findings}}<tr>
+<td class="{{.Severity}}">{{.Severity}}</td>
+<td>{{.Kind}}</td>
+<td>{{if .Anchor}}<a href="#{{.Anchor}}" class="finding">{{.Id}}</a>{{else}}{{.Id}}{{end}}</td>
+<td>{{.Name}}</td>
+<td>{{.Message}}</td>
+<td>{{.Detail}}</td>
+</tr>
+{{end}}</table>
+<script>
+(function () {
+  var tree = document.querySelector("ul.tree");
+
+  tree.addEventListener("click", function (event) {
+    if (event.target.classList.contains("toggle")) {
+      event.target.parentNode.classList.toggle("collapsed");
+    }
+  });
+
+  function reveal(li) {
+    for (var p = li.parentNode; p && p !== tree; p = p.parentNode) {
+      if (p.tagName === "LI") {
+        p.classList.remove("collapsed", "hidden");
+      }
+    }
+  }
+
+  function filter(li, query) {
+    var label = li.querySelector(".node");
+    var matches = query !== "" && label.textContent.toLowerCase().indexOf(query) >= 0;
+    var childMatches = false;
+    var children = li.querySelectorAll(":scope > ul > li");
+    for (var i = 0; i < children.length; i++) {
+      childMatches = filter(children[i], query) || childMatches;
+    }
+    label.classList.toggle("match", matches);
+    li.classList.toggle("hidden", query !== "" && !matches && !childMatches);
+    if (childMatches) {
+      li.classList.remove("collapsed");
+    }
+    return matches || childMatches;
+  }
+
+  document.getElementById("search").addEventListener("input", function (event) {
+    var query = event.target.value.trim().toLowerCase();
+    var roots = tree.querySelectorAll(":scope > li");
+    for (var i = 0; i < roots.length; i++) {
+      filter(roots[i], query);
+    }
+  });
+
+  function target() {
+    var previous = document.querySelector(".node.target");
+    if (previous) {
+      previous.classList.remove("target");
+    }
+    var li = document.getElementById(location.hash.substring(1));
+    if (li && li.tagName === "LI") {
+      reveal(li);
+      li.classList.remove("hidden");
+      li.querySelector(".node").classList.add("target");
+      li.scrollIntoView();
+    }
+  }
+
+  window.addEventListener("hashchange", target);
+  target();
+})();
+</script>
+</body>
+</html>
+{{define "node"}}<li{{if not .Cycle}} id="{{anchor .Id}}"{{end}}>{{if .Children}}<span class="toggle">&#9662;</span>{{else}}<span class="toggle"></span>{{end}}<span class="node {{if .Cycle}}cycle{{else}}{{.State}}{{end}}">{{.Label}}{{if .Cycle}} (cycle){{end}}</span>{{if .Messages}}<span class="messages">{{range $i, $m := .Messages}}{{if $i}}; {{end}}{{$m}}{{end}}</span>{{end}}{{if .Children}}
+<ul>
+{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}</li>
+{{end}}`))
@@ -0,0 +1,64 @@
+package orgstruct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", KUId: "ku1", FSId: "fs1", OrgKZ: "A", Type: "Bereich"},
+		"oe2": {Id: "oe2", KUId: "ku1", ParentLId: "oe1", OrgKZ: "A 1", OrgName1: "<Team>"},
+	}
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1"}}
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := WriteHTMLReport(&buffer, oeMap, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+	html := buffer.String()
+
+	for _, expected := range []string{
+		`<li id="oe-oe1">`,
+		`<span class="node ok">A [Bereich] (oe1)</span>`,
+		`<span class="node error">A 1 &lt;Team&gt; (oe2)</span><span class="messages">no related FS id</span>`,
+		`<a href="#oe-oe2" class="finding">oe2</a>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("wanted %s in:\n%s", expected, html)
+		}
+	}
+
+	for _, external := range []string{"<link", "src="} {
+		if strings.Contains(html, external) {
+			t.Errorf("wanted no external resources, found %s", external)
+		}
+	}
+}
+
+func TestWriteHTMLReportCycleIds(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"a": {Id: "a", ParentLId: "c"},
+		"c": {Id: "c", ParentLId: "d"},
+		"d": {Id: "d", ParentLId: "c"},
+	}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := WriteHTMLReport(&buffer, oeMap, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	for _, id := range []string{"a", "c", "d"} {
+		if count := strings.Count(buffer.String(), `id="oe-`+id+`"`); count != 1 {
+			t.Errorf("wanted one node with id oe-%s, got: %d", id, count)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package orgstruct parses the FS, KU and OE exports of the organisational
+// structure, links them to trees and validates them.
+//
+// A typical use is
+//
+//	m, err := orgstruct.Load("XML_FS.xml", "XML_KU.xml", "XML_OE.xml")
+//	if err != nil {
+//		return err
+//	}
+//	errors := m.Analyze(orgstruct.DefaultOptions())
+//	for _, f := range errors.Findings() {
+//		fmt.Println(f.Kind, f.Id, f.Message)
+//	}
+package orgstruct
+
+import (
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// Model holds the items of one set of exports. OE, KU and FS hold one
+// version per id: the latest one after Parse or Load, or the one valid at a
+// date after SelectAt.
+type Model struct {
+	OE map[string]*OEItem
+	KU map[string]*KUItem
+	FS map[string]*FSItem
+
+	// OEVersions, KUVersions and FSVersions hold all versions of every id,
+	// ordered by the start of their validity.
+	OEVersions map[string][]*OEItem
+	KUVersions map[string][]*KUItem
+	FSVersions map[string][]*FSItem
+
+	// OEDuplicates, KUDuplicates and FSDuplicates hold the rows dropped
+	// because they repeat the id and validity of an earlier row.
+	OEDuplicates []*OEItem
+	KUDuplicates []*KUItem
+	FSDuplicates []*FSItem
+
+	// parseErrors holds copies of the errors found while parsing, which
+	// every Analyze starts from.
+	parseErrors map[*ItemWithError][]*Error
+	// at is the date given to SelectAt, if any.
+	at    time.Time
+	built bool
+}
+
+// DefaultOptions returns the options used unless configured otherwise.
+func DefaultOptions() Options {
+	return defaultOptions
+}
+
+func newModel(oeVersions map[string][]*OEItem, kuVersions map[string][]*KUItem, fsVersions map[string][]*FSItem) *Model {
+	m := &Model{OEVersions: oeVersions, KUVersions: kuVersions, FSVersions: fsVersions}
+	m.OE, m.KU, m.FS = latestVersions(oeVersions, kuVersions, fsVersions)
+	return m
+}
+
+// Parse reads the FS, KU and OE exports.
+func Parse(fs io.Reader, ku io.Reader, oe io.Reader) (*Model, error) {
+	fsVersions, fsDuplicates, err := parseFSReader(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	kuVersions, kuDuplicates, err := parseKUReader(ku)
+	if err != nil {
+		return nil, err
+	}
+
+	oeVersions, oeDuplicates, err := parseOEReader(oe)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newModel(oeVersions, kuVersions, fsVersions)
+	m.OEDuplicates, m.KUDuplicates, m.FSDuplicates = oeDuplicates, kuDuplicates, fsDuplicates
+	m.recordParseErrors()
+	return m, nil
+}
+
+// Load reads the FS, KU and OE exports from files. Parse errors carry the
+// path of the file.
+func Load(fsPath string, kuPath string, oePath string) (*Model, error) {
+	fsVersions, fsDuplicates, err := parseFS(fsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	kuVersions, kuDuplicates, err := parseKU(kuPath)
+	if err != nil {
+		return nil, err
+	}
+
+	oeVersions, oeDuplicates, err := parseOE(oePath)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newModel(oeVersions, kuVersions, fsVersions)
+	m.OEDuplicates, m.KUDuplicates, m.FSDuplicates = oeDuplicates, kuDuplicates, fsDuplicates
+	m.recordParseErrors()
+	return m, nil
+}
+
+// LoadDir reads XML_FS.xml, XML_KU.xml and XML_OE.xml from a directory.
+func LoadDir(dir string) (*Model, error) {
+	return Load(filepath.Join(dir, "XML_FS.xml"), filepath.Join(dir, "XML_KU.xml"), filepath.Join(dir, "XML_OE.xml"))
+}
+
+// items calls f for every version and every dropped duplicate row.
+func (m *Model) items(f func(item *ItemWithError)) {
+	for _, versions := range m.OEVersions {
+		for _, v := range versions {
+			f(&v.ItemWithError)
+		}
+	}
+	for _, versions := range m.KUVersions {
+		for _, v := range versions {
+			f(&v.ItemWithError)
+		}
+	}
+	for _, versions := range m.FSVersions {
+		for _, v := range versions {
+			f(&v.ItemWithError)
+		}
+	}
+	for _, v := range m.OEDuplicates {
+		f(&v.ItemWithError)
+	}
+	for _, v := range m.KUDuplicates {
+		f(&v.ItemWithError)
+	}
+	for _, v := range m.FSDuplicates {
+		f(&v.ItemWithError)
+	}
+}
+
+func (m *Model) recordParseErrors() {
+	m.parseErrors = make(map[*ItemWithError][]*Error)
+	m.items(func(item *ItemWithError) {
+		if len(item.Errors) > 0 {
+			m.parseErrors[item] = append([]*Error(nil), item.Errors...)
+		}
+	})
+}
+
+// SelectAt replaces OE, KU and FS with the versions valid at the given date,
+// dropping ids without such a version. Trees built before are dropped and
+// built again for the selected versions.
+func (m *Model) SelectAt(at time.Time) {
+	m.OE, m.KU, m.FS = versionsValidAt(at, m.OEVersions, m.KUVersions, m.FSVersions)
+	m.at = at
+	if m.built {
+		m.unlink()
+		m.built = false
+	}
+}
+
+// selectedDuplicates keeps the duplicate rows of the selected ids and, after
+// SelectAt, only those valid at its date.
+func (m *Model) selectedDuplicates() ([]*OEItem, []*KUItem, []*FSItem) {
+	selected := func(ok bool, validity interval) bool {
+		return ok && (m.at.IsZero() || validity.includes(m.at))
+	}
+
+	var oeDuplicates []*OEItem
+	for _, v := range m.OEDuplicates {
+		if _, ok := m.OE[v.Id]; selected(ok, v.validity()) {
+			oeDuplicates = append(oeDuplicates, v)
+		}
+	}
+
+	var kuDuplicates []*KUItem
+	for _, v := range m.KUDuplicates {
+		if _, ok := m.KU[v.Id]; selected(ok, v.validity()) {
+			kuDuplicates = append(kuDuplicates, v)
+		}
+	}
+
+	var fsDuplicates []*FSItem
+	for _, v := range m.FSDuplicates {
+		if _, ok := m.FS[v.Id]; selected(ok, v.validity()) {
+			fsDuplicates = append(fsDuplicates, v)
+		}
+	}
+
+	return oeDuplicates, kuDuplicates, fsDuplicates
+}
+
+// unlink drops the links set by Build from all versions.
+func (m *Model) unlink() {
+	for _, versions := range m.OEVersions {
+		for _, v := range versions {
+			v.KU, v.FS, v.ParentL, v.ParentF = nil, nil, nil, nil
+			v.LChildren, v.FChildren = nil, nil
+		}
+	}
+	for _, versions := range m.KUVersions {
+		for _, v := range versions {
+			v.Parent, v.Children, v.OE = nil, nil, nil
+		}
+	}
+	for _, versions := range m.FSVersions {
+		for _, v := range versions {
+			v.Parent, v.Children, v.OE = nil, nil, nil
+		}
+	}
+}
+
+// Build links the items to the KU, FS, OE-L and OE-F trees. It only does so
+// once.
+func (m *Model) Build() {
+	if m.built {
+		return
+	}
+	buildTrees(m.OE, m.KU, m.FS)
+	m.built = true
+}
+
+// Analyze builds the trees unless done before and validates them, the
+// versions and the duplicate rows. The errors of earlier runs are dropped
+// from the items, so it can run again with other options.
+func (m *Model) Analyze(options Options) *Errors {
+	m.Build()
+	m.items(func(item *ItemWithError) {
+		item.Errors = append([]*Error(nil), m.parseErrors[item]...)
+	})
+	result := analyzeTreesWith(options, m.OE, m.KU, m.FS)
+	oeVersions, kuVersions, fsVersions := selectedVersions(m.OE, m.KU, m.FS, m.OEVersions, m.KUVersions, m.FSVersions)
+	analyzeVersions(oeVersions, kuVersions, fsVersions, result)
+	oeDuplicates, kuDuplicates, fsDuplicates := m.selectedDuplicates()
+	reportDuplicates(oeDuplicates, kuDuplicates, fsDuplicates, result)
+	return result
+}
+
+// DiffModels compares the items of an old and a new model.
+func DiffModels(old *Model, next *Model) *Diff {
+	return diffSnapshots(old.OE, old.KU, old.FS, next.OE, next.KU, next.FS)
+}
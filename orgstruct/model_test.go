@@ -0,0 +1,113 @@
+package orgstruct
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const modelFS = `<vw_FS>
+  <FS s_NODE_FS_ID="fs1" FSLANG="Vorstand" DEPTH="0" GAB="2000-01-01T00:00:00" GBIS="9999-12-31T00:00:00" />
+</vw_FS>`
+
+const modelKU = `<vw_KU>
+  <KU s_NODE_KU_ID="ku1" KULANG="Konzern" DEPTH="0" GAB="2000-01-01T00:00:00" GBIS="9999-12-31T00:00:00" />
+</vw_KU>`
+
+const modelOE = `<OETBL>
+  <OE s_OE_ID="oe1" s_KU_ID="ku1" s_FS_ID="fs1" Gültig_x0020_ab="2000-01-01T00:00:00" Gültig_x0020_bis="2009-12-31T00:00:00" Org-Kz="A" />
+  <OE s_OE_ID="oe1" s_KU_ID="ku1" s_FS_ID="fs1" Gültig_x0020_ab="2010-01-01T00:00:00" Gültig_x0020_bis="9999-12-31T00:00:00" Org-Kz="B" />
+  <OE s_OE_ID="oe2" s_KU_ID="ku1" s_PARENTOE_L_ID="oe1" Gültig_x0020_ab="2010-01-01T00:00:00" Gültig_x0020_bis="9999-12-31T00:00:00" Org-Kz="C" />
+</OETBL>`
+
+func parseModel(t *testing.T) *Model {
+	m, err := Parse(strings.NewReader(modelFS), strings.NewReader(modelKU), strings.NewReader(modelOE))
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+	return m
+}
+
+func TestModelAnalyze(t *testing.T) {
+	m := parseModel(t)
+	if len(m.OE) != 2 || len(m.OEVersions["oe1"]) != 2 || m.OE["oe1"].OrgKZ != "B" {
+		t.Fatalf("wanted the latest of 2 versions of oe1, got: %+v", m.OE["oe1"])
+	}
+
+	result := m.Analyze(DefaultOptions())
+	if m.OE["oe2"].ParentL != m.OE["oe1"] {
+		t.Errorf("wanted trees to be built")
+	}
+
+	if result.Count() != 1 || result.OEErrors[0].OE.Id != "oe2" || result.OEErrors[0].Message != NO_RELATED_FS_ID {
+		t.Errorf("wanted only the missing FS of oe2, got: %d errors", result.Count())
+	}
+
+	m.Build()
+	if len(m.OE["oe1"].LChildren) != 1 {
+		t.Errorf("wanted Build to link the trees once, got: %d children", len(m.OE["oe1"].LChildren))
+	}
+}
+
+func TestModelAnalyzeAgain(t *testing.T) {
+	m := parseModel(t)
+	m.Analyze(DefaultOptions())
+	result := m.Analyze(DefaultOptions())
+
+	if result.Count() != 1 {
+		t.Errorf("wanted 1 error, got: %d", result.Count())
+	}
+	assertErrorCount(1, m.OE["oe2"].Errors, t)
+}
+
+func TestModelSelectAtAfterBuild(t *testing.T) {
+	m := parseModel(t)
+	m.Build()
+	m.SelectAt(time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC))
+	m.Analyze(DefaultOptions())
+
+	if len(m.OE) != 1 || len(m.OE["oe1"].LChildren) != 0 {
+		t.Errorf("wanted only oe1 without children, got: %d items", len(m.OE))
+	}
+	if len(m.OEVersions["oe1"][1].LChildren) != 0 {
+		t.Errorf("wanted the links of the latest version to be dropped")
+	}
+}
+
+func TestModelSelectAt(t *testing.T) {
+	m := parseModel(t)
+	m.SelectAt(time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if m.OE["oe1"].OrgKZ != "A" {
+		t.Errorf("wanted the version valid in 2005, got: %s", m.OE["oe1"].OrgKZ)
+	}
+}
+
+func TestParseModelError(t *testing.T) {
+	_, err := Parse(strings.NewReader(modelFS), strings.NewReader("<vw_KU><KU"), strings.NewReader(modelOE))
+	if err == nil {
+		t.Errorf("wanted error, got none")
+	}
+}
+
+func TestModelSelectAtDuplicates(t *testing.T) {
+	oe := strings.Replace(modelOE, "</OETBL>", `  <OE s_OE_ID="oe2" s_KU_ID="ku1" s_PARENTOE_L_ID="oe1" Gültig_x0020_ab="2010-01-01T00:00:00" Gültig_x0020_bis="9999-12-31T00:00:00" Org-Kz="C" />
+</OETBL>`, 1)
+	m, err := Parse(strings.NewReader(modelFS), strings.NewReader(modelKU), strings.NewReader(oe))
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	if n := len(m.Analyze(DefaultOptions()).Filter(isDuplicateId)); n != 2 {
+		t.Errorf("wanted the kept and the dropped row of oe2, got: %d", n)
+	}
+
+	m.SelectAt(time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC))
+	if n := len(m.Analyze(DefaultOptions()).Filter(isDuplicateId)); n != 0 {
+		t.Errorf("wanted no duplicates of oe2 before it is valid, got: %d", n)
+	}
+}
+
+func isDuplicateId(f *Finding) bool {
+	return f.Type == DuplicateId
+}
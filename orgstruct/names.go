@@ -0,0 +1,50 @@
+package orgstruct
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+const (
+	KU_NAME_MISMATCH = "KU name differs from related KU"
+	FS_NAME_MISMATCH = "FS name differs from related FS"
+)
+
+func addNameMismatchError(item *ItemWithError, message string, reference string, detail string) *Error {
+	e := &Error{Message: message, Type: NameMismatch, Reference: reference, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+// normalize applies the enabled normalisations to name.
+func (n NameNormalization) normalize(name string) string {
+	if n.Entities {
+		name = html.UnescapeString(name)
+	}
+	if n.Whitespace {
+		name = strings.Join(strings.Fields(name), " ")
+	}
+	if n.Case {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+func (n NameNormalization) equal(a string, b string) bool {
+	return n.normalize(a) == n.normalize(b)
+}
+
+// analyzeNamesOE checks the denormalised KU and FS names of an OE item
+// against the names of the linked items. Empty names are not checked.
+func analyzeNamesOE(v *OEItem, names NameNormalization, result *Errors) {
+	if v.KU != nil && v.KUName != "" && !names.equal(v.KUName, v.KU.NameLong) {
+		detail := fmt.Sprintf("%q, KU has %q", v.KUName, v.KU.NameLong)
+		addOEError(v, addNameMismatchError(&v.ItemWithError, KU_NAME_MISMATCH, v.KUId, detail), result)
+	}
+
+	if v.FS != nil && v.FSName != "" && !names.equal(v.FSName, v.FS.NameLong) && !names.equal(v.FSName, v.FS.NameShort) {
+		detail := fmt.Sprintf("%q, FS has %q (%q)", v.FSName, v.FS.NameLong, v.FS.NameShort)
+		addOEError(v, addNameMismatchError(&v.ItemWithError, FS_NAME_MISMATCH, v.FSId, detail), result)
+	}
+}
@@ -0,0 +1,71 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestNameNormalization(t *testing.T) {
+	all := NameNormalization{Whitespace: true, Case: true, Entities: true}
+	cases := []struct {
+		names NameNormalization
+		a     string
+		b     string
+		equal bool
+	}{
+		{all, "DB Station&amp;Service AG", "db station&service  AG ", true},
+		{NameNormalization{}, "DB Station&Service AG", "DB Station&Service AG", true},
+		{NameNormalization{}, "DB Station&amp;Service AG", "DB Station&Service AG", false},
+		{NameNormalization{Entities: true}, "DB Station&amp;Service AG", "DB Station&Service AG", true},
+		{NameNormalization{Entities: true}, "DB Station&amp;Service AG", "DB Station & Service AG", false},
+		{NameNormalization{Whitespace: true}, " Personen\tbahnhöfe", "Personen bahnhöfe", true},
+		{NameNormalization{Whitespace: true}, "Personenbahnhöfe", "personenbahnhöfe", false},
+		{NameNormalization{Case: true}, "Personenbahnhöfe", "PERSONENBAHNHÖFE", true},
+	}
+
+	for _, c := range cases {
+		if c.names.equal(c.a, c.b) != c.equal {
+			t.Errorf("wanted %q and %q to be equal with %+v: %t", c.a, c.b, c.names, c.equal)
+		}
+	}
+}
+
+func TestParseNameNormalization(t *testing.T) {
+	names, err := ParseNameNormalization("whitespace, entities")
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	if !names.Whitespace || names.Case || !names.Entities {
+		t.Errorf("unexpected normalization: %+v", names)
+	}
+
+	if _, err := ParseNameNormalization("accents"); err == nil {
+		t.Errorf("wanted error for unknown normalization")
+	}
+}
+
+func TestOENameMismatch(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1", NameLong: "DB Station&Service AG"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1", NameLong: "Personenbahnhöfe", NameShort: "PBH"}}
+	oeMap := make(map[string]*OEItem)
+
+	oeMap["oe1"] = &OEItem{Id: "oe1", KUId: "ku1", FSId: "fs1", KUName: "DB Station&amp;Service AG", FSName: "pbh"}
+	oeMap["oe2"] = &OEItem{Id: "oe2", KUId: "ku1", FSId: "fs1", KUName: "DB Netz AG", FSName: "Fernverkehr"}
+	oeMap["oe3"] = &OEItem{Id: "oe3", KUId: "ku1", FSId: "fs1"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertErrorCount(0, oeMap["oe1"].Errors, t)
+	assertErrorCount(0, oeMap["oe3"].Errors, t)
+	assertErrorCount(2, oeMap["oe2"].Errors, t)
+	assertError(Error{Message: KU_NAME_MISMATCH, Type: NameMismatch}, oeMap["oe2"].Errors, t)
+	assertError(Error{Message: FS_NAME_MISMATCH, Type: NameMismatch}, oeMap["oe2"].Errors, t)
+
+	for _, item := range oeMap {
+		item.Errors = nil
+	}
+	analyzeTreesWith(Options{}, oeMap, kuMap, fsMap)
+
+	assertErrorCount(2, oeMap["oe1"].Errors, t)
+}
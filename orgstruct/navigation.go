@@ -0,0 +1,371 @@
+package orgstruct
+
+// The navigation methods work on the trees linked by buildTrees. They stop at
+// cycles, so they terminate on broken data as well. All of them accept a nil
+// item and return nil for it.
+
+// node is one item in one of the trees. The KU and FS trees and both OE
+// hierarchies wrap their items in a node, so the walks below are shared.
+type node interface {
+	parent() node
+	children() []node
+}
+
+// pathToRoot returns the node and its parents up to the root. It stops
+// before a node that would repeat, so it ends early in a cycle.
+func pathToRoot(n node) []node {
+	var path []node
+	visited := make(map[node]bool)
+	for current := n; current != nil && !visited[current]; current = current.parent() {
+		visited[current] = true
+		path = append(path, current)
+	}
+	return path
+}
+
+// ancestors returns the parents of the node, the closest first.
+func ancestors(n node) []node {
+	path := pathToRoot(n)
+	if len(path) == 0 {
+		return nil
+	}
+	return path[1:]
+}
+
+// root returns the last node of pathToRoot.
+func root(n node) node {
+	path := pathToRoot(n)
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// descendants returns the children of the node, their children and so on,
+// level by level. Nodes are returned once, the node itself never.
+func descendants(n node) []node {
+	if n == nil {
+		return nil
+	}
+
+	var result []node
+	visited := map[node]bool{n: true}
+	queue := []node{n}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range current.children() {
+			if !visited[child] {
+				visited[child] = true
+				result = append(result, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return result
+}
+
+// siblings returns the other children of the parent of the node.
+func siblings(n node) []node {
+	if n == nil || n.parent() == nil {
+		return nil
+	}
+
+	var result []node
+	for _, child := range n.parent().children() {
+		if child != n {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// lowestCommonAncestor returns the closest node on the paths to root of
+// both nodes, which may be one of them. It returns nil if the nodes are in
+// different trees.
+func lowestCommonAncestor(n node, other node) node {
+	onPath := make(map[node]bool)
+	for _, ancestor := range pathToRoot(n) {
+		onPath[ancestor] = true
+	}
+
+	for _, ancestor := range pathToRoot(other) {
+		if onPath[ancestor] {
+			return ancestor
+		}
+	}
+	return nil
+}
+
+// kuNode is a KU item in the KU tree.
+type kuNode struct{ item *KUItem }
+
+func newKUNode(item *KUItem) node {
+	if item == nil {
+		return nil
+	}
+	return kuNode{item}
+}
+
+func (n kuNode) parent() node { return newKUNode(n.item.Parent) }
+
+func (n kuNode) children() []node {
+	nodes := make([]node, len(n.item.Children))
+	for i, child := range n.item.Children {
+		nodes[i] = kuNode{child}
+	}
+	return nodes
+}
+
+func kuNodeItem(n node) *KUItem {
+	if n == nil {
+		return nil
+	}
+	return n.(kuNode).item
+}
+
+func kuNodeItems(nodes []node) []*KUItem {
+	if nodes == nil {
+		return nil
+	}
+	items := make([]*KUItem, len(nodes))
+	for i, n := range nodes {
+		items[i] = kuNodeItem(n)
+	}
+	return items
+}
+
+// PathToRoot returns the item and its parents up to the root. It stops
+// before an item that would repeat, so it ends early in a cycle.
+func (item *KUItem) PathToRoot() []*KUItem {
+	return kuNodeItems(pathToRoot(newKUNode(item)))
+}
+
+// Ancestors returns the parents of the item, the closest first.
+func (item *KUItem) Ancestors() []*KUItem {
+	return kuNodeItems(ancestors(newKUNode(item)))
+}
+
+// Root returns the last item of PathToRoot.
+func (item *KUItem) Root() *KUItem {
+	return kuNodeItem(root(newKUNode(item)))
+}
+
+// Descendants returns the children of the item, their children and so on,
+// level by level. Items are returned once, the item itself never.
+func (item *KUItem) Descendants() []*KUItem {
+	return kuNodeItems(descendants(newKUNode(item)))
+}
+
+// Siblings returns the other children of the parent of the item.
+func (item *KUItem) Siblings() []*KUItem {
+	return kuNodeItems(siblings(newKUNode(item)))
+}
+
+// LowestCommonAncestor returns the closest item on the paths to root of
+// both items, which may be one of them. It returns nil if the items are in
+// different trees.
+func (item *KUItem) LowestCommonAncestor(other *KUItem) *KUItem {
+	return kuNodeItem(lowestCommonAncestor(newKUNode(item), newKUNode(other)))
+}
+
+// fsNode is an FS item in the FS tree.
+type fsNode struct{ item *FSItem }
+
+func newFSNode(item *FSItem) node {
+	if item == nil {
+		return nil
+	}
+	return fsNode{item}
+}
+
+func (n fsNode) parent() node { return newFSNode(n.item.Parent) }
+
+func (n fsNode) children() []node {
+	nodes := make([]node, len(n.item.Children))
+	for i, child := range n.item.Children {
+		nodes[i] = fsNode{child}
+	}
+	return nodes
+}
+
+func fsNodeItem(n node) *FSItem {
+	if n == nil {
+		return nil
+	}
+	return n.(fsNode).item
+}
+
+func fsNodeItems(nodes []node) []*FSItem {
+	if nodes == nil {
+		return nil
+	}
+	items := make([]*FSItem, len(nodes))
+	for i, n := range nodes {
+		items[i] = fsNodeItem(n)
+	}
+	return items
+}
+
+// PathToRoot returns the item and its parents up to the root. It stops
+// before an item that would repeat, so it ends early in a cycle.
+func (item *FSItem) PathToRoot() []*FSItem {
+	return fsNodeItems(pathToRoot(newFSNode(item)))
+}
+
+// Ancestors returns the parents of the item, the closest first.
+func (item *FSItem) Ancestors() []*FSItem {
+	return fsNodeItems(ancestors(newFSNode(item)))
+}
+
+// Root returns the last item of PathToRoot.
+func (item *FSItem) Root() *FSItem {
+	return fsNodeItem(root(newFSNode(item)))
+}
+
+// Descendants returns the children of the item, their children and so on,
+// level by level. Items are returned once, the item itself never.
+func (item *FSItem) Descendants() []*FSItem {
+	return fsNodeItems(descendants(newFSNode(item)))
+}
+
+// Siblings returns the other children of the parent of the item.
+func (item *FSItem) Siblings() []*FSItem {
+	return fsNodeItems(siblings(newFSNode(item)))
+}
+
+// LowestCommonAncestor returns the closest item on the paths to root of
+// both items, which may be one of them. It returns nil if the items are in
+// different trees.
+func (item *FSItem) LowestCommonAncestor(other *FSItem) *FSItem {
+	return fsNodeItem(lowestCommonAncestor(newFSNode(item), newFSNode(other)))
+}
+
+// oeLNode is an OE item in the L hierarchy.
+type oeLNode struct{ item *OEItem }
+
+func newOELNode(item *OEItem) node {
+	if item == nil {
+		return nil
+	}
+	return oeLNode{item}
+}
+
+func (n oeLNode) parent() node { return newOELNode(n.item.ParentL) }
+
+func (n oeLNode) children() []node {
+	nodes := make([]node, len(n.item.LChildren))
+	for i, child := range n.item.LChildren {
+		nodes[i] = oeLNode{child}
+	}
+	return nodes
+}
+
+// oeFNode is an OE item in the F hierarchy.
+type oeFNode struct{ item *OEItem }
+
+func newOEFNode(item *OEItem) node {
+	if item == nil {
+		return nil
+	}
+	return oeFNode{item}
+}
+
+func (n oeFNode) parent() node { return newOEFNode(n.item.ParentF) }
+
+func (n oeFNode) children() []node {
+	nodes := make([]node, len(n.item.FChildren))
+	for i, child := range n.item.FChildren {
+		nodes[i] = oeFNode{child}
+	}
+	return nodes
+}
+
+func oeNodeItem(n node) *OEItem {
+	switch n := n.(type) {
+	case oeLNode:
+		return n.item
+	case oeFNode:
+		return n.item
+	}
+	return nil
+}
+
+func oeNodeItems(nodes []node) []*OEItem {
+	if nodes == nil {
+		return nil
+	}
+	items := make([]*OEItem, len(nodes))
+	for i, n := range nodes {
+		items[i] = oeNodeItem(n)
+	}
+	return items
+}
+
+// PathToRootL returns the item and its L parents up to the root. It stops
+// before an item that would repeat, so it ends early in a cycle.
+func (item *OEItem) PathToRootL() []*OEItem {
+	return oeNodeItems(pathToRoot(newOELNode(item)))
+}
+
+// AncestorsL returns the L parents of the item, the closest first.
+func (item *OEItem) AncestorsL() []*OEItem {
+	return oeNodeItems(ancestors(newOELNode(item)))
+}
+
+// RootL returns the last item of PathToRootL.
+func (item *OEItem) RootL() *OEItem {
+	return oeNodeItem(root(newOELNode(item)))
+}
+
+// DescendantsL returns the L children of the item, their children and so on,
+// level by level. Items are returned once, the item itself never.
+func (item *OEItem) DescendantsL() []*OEItem {
+	return oeNodeItems(descendants(newOELNode(item)))
+}
+
+// SiblingsL returns the other L children of the parent of the item.
+func (item *OEItem) SiblingsL() []*OEItem {
+	return oeNodeItems(siblings(newOELNode(item)))
+}
+
+// LowestCommonAncestorL returns the closest item on the L paths to root of
+// both items, which may be one of them. It returns nil if the items are in
+// different trees.
+func (item *OEItem) LowestCommonAncestorL(other *OEItem) *OEItem {
+	return oeNodeItem(lowestCommonAncestor(newOELNode(item), newOELNode(other)))
+}
+
+// PathToRootF returns the item and its F parents up to the root. It stops
+// before an item that would repeat, so it ends early in a cycle.
+func (item *OEItem) PathToRootF() []*OEItem {
+	return oeNodeItems(pathToRoot(newOEFNode(item)))
+}
+
+// AncestorsF returns the F parents of the item, the closest first.
+func (item *OEItem) AncestorsF() []*OEItem {
+	return oeNodeItems(ancestors(newOEFNode(item)))
+}
+
+// RootF returns the last item of PathToRootF.
+func (item *OEItem) RootF() *OEItem {
+	return oeNodeItem(root(newOEFNode(item)))
+}
+
+// DescendantsF returns the F children of the item, their children and so on,
+// level by level. Items are returned once, the item itself never.
+func (item *OEItem) DescendantsF() []*OEItem {
+	return oeNodeItems(descendants(newOEFNode(item)))
+}
+
+// SiblingsF returns the other F children of the parent of the item.
+func (item *OEItem) SiblingsF() []*OEItem {
+	return oeNodeItems(siblings(newOEFNode(item)))
+}
+
+// LowestCommonAncestorF returns the closest item on the F paths to root of
+// both items, which may be one of them. It returns nil if the items are in
+// different trees.
+func (item *OEItem) LowestCommonAncestorF(other *OEItem) *OEItem {
+	return oeNodeItem(lowestCommonAncestor(newOEFNode(item), newOEFNode(other)))
+}
@@ -0,0 +1,131 @@
+package orgstruct
+
+import (
+	"sort"
+	"testing"
+)
+
+func kuIds(items []*KUItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+	return ids
+}
+
+func sortedOEIds(items []*OEItem) []string {
+	ids := oeIds(items)
+	sort.Strings(ids)
+	return ids
+}
+
+func oeIds(items []*OEItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+	return ids
+}
+
+func assertIds(t *testing.T, expected []string, ids []string) {
+	t.Helper()
+	if len(expected) != len(ids) {
+		t.Errorf("wanted %v, got: %v", expected, ids)
+		return
+	}
+	for i := range ids {
+		if expected[i] != ids[i] {
+			t.Errorf("wanted %v, got: %v", expected, ids)
+			return
+		}
+	}
+}
+
+func TestNavigationKU(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1"},
+		"ku2": {Id: "ku2", ParentId: "ku1"},
+		"ku3": {Id: "ku3", ParentId: "ku1"},
+		"ku4": {Id: "ku4", ParentId: "ku2"},
+		"ku5": {Id: "ku5"},
+	}
+	buildTrees(map[string]*OEItem{}, kuMap, map[string]*FSItem{})
+
+	assertIds(t, []string{"ku4", "ku2", "ku1"}, kuIds(kuMap["ku4"].PathToRoot()))
+	assertIds(t, []string{"ku2", "ku1"}, kuIds(kuMap["ku4"].Ancestors()))
+	assertIds(t, []string{}, kuIds(kuMap["ku1"].Ancestors()))
+	assertIds(t, []string{"ku3"}, kuIds(kuMap["ku2"].Siblings()))
+	assertIds(t, []string{}, kuIds(kuMap["ku1"].Siblings()))
+
+	descendants := kuIds(kuMap["ku1"].Descendants())
+	if len(descendants) != 3 || descendants[2] != "ku4" {
+		t.Errorf("wanted ku2 and ku3 before ku4, got: %v", descendants)
+	}
+
+	if kuMap["ku4"].Root() != kuMap["ku1"] {
+		t.Errorf("wanted root ku1, got: %s", kuMap["ku4"].Root().Id)
+	}
+
+	if lca := kuMap["ku4"].LowestCommonAncestor(kuMap["ku3"]); lca != kuMap["ku1"] {
+		t.Errorf("wanted ku1 as lowest common ancestor, got: %v", lca)
+	}
+
+	if lca := kuMap["ku4"].LowestCommonAncestor(kuMap["ku2"]); lca != kuMap["ku2"] {
+		t.Errorf("wanted ku2 as lowest common ancestor, got: %v", lca)
+	}
+
+	if lca := kuMap["ku4"].LowestCommonAncestor(kuMap["ku5"]); lca != nil {
+		t.Errorf("wanted no common ancestor, got: %s", lca.Id)
+	}
+}
+
+func TestNavigationOECycle(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", ParentLId: "oe2", ParentFId: "oe3"},
+		"oe2": {Id: "oe2", ParentLId: "oe1"},
+		"oe3": {Id: "oe3"},
+		"oe4": {Id: "oe4", ParentLId: "oe1", ParentFId: "oe3"},
+	}
+	buildTrees(oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+
+	assertIds(t, []string{"oe4", "oe1", "oe2"}, oeIds(oeMap["oe4"].PathToRootL()))
+	assertIds(t, []string{"oe4", "oe3"}, oeIds(oeMap["oe4"].PathToRootF()))
+	assertIds(t, []string{"oe2", "oe4"}, sortedOEIds(oeMap["oe1"].DescendantsL()))
+	assertIds(t, []string{"oe1", "oe4"}, sortedOEIds(oeMap["oe3"].DescendantsF()))
+	assertIds(t, []string{"oe1"}, oeIds(oeMap["oe4"].SiblingsF()))
+
+	if oeMap["oe1"].RootL() != oeMap["oe2"] {
+		t.Errorf("wanted the cycle to end at oe2, got: %s", oeMap["oe1"].RootL().Id)
+	}
+
+	// oe2 is an ancestor of oe4 through the cycle
+	if lca := oeMap["oe4"].LowestCommonAncestorL(oeMap["oe2"]); lca != oeMap["oe2"] {
+		t.Errorf("wanted oe2 as lowest common ancestor, got: %v", lca)
+	}
+}
+
+func TestNavigationNil(t *testing.T) {
+	var ku *KUItem
+	var fs *FSItem
+	var oe *OEItem
+
+	if ku.PathToRoot() != nil || ku.Ancestors() != nil || ku.Root() != nil ||
+		ku.Descendants() != nil || ku.Siblings() != nil || ku.LowestCommonAncestor(&KUItem{}) != nil {
+		t.Errorf("wanted nil for a nil KU item")
+	}
+
+	if fs.PathToRoot() != nil || fs.Ancestors() != nil || fs.Root() != nil ||
+		fs.Descendants() != nil || fs.Siblings() != nil || fs.LowestCommonAncestor(&FSItem{}) != nil {
+		t.Errorf("wanted nil for a nil FS item")
+	}
+
+	if oe.PathToRootL() != nil || oe.AncestorsL() != nil || oe.RootL() != nil ||
+		oe.DescendantsL() != nil || oe.SiblingsL() != nil || oe.LowestCommonAncestorL(&OEItem{}) != nil {
+		t.Errorf("wanted nil for a nil OE item in the L hierarchy")
+	}
+
+	if oe.PathToRootF() != nil || oe.AncestorsF() != nil || oe.RootF() != nil ||
+		oe.DescendantsF() != nil || oe.SiblingsF() != nil || oe.LowestCommonAncestorF(&OEItem{}) != nil {
+		t.Errorf("wanted nil for a nil OE item in the F hierarchy")
+	}
+}
@@ -0,0 +1,57 @@
+package orgstruct
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NameNormalization tells which differences are ignored when comparing the
+// denormalised names of OE items with the names of their KU and FS.
+type NameNormalization struct {
+	// Whitespace ignores leading, trailing and repeated whitespace.
+	Whitespace bool
+	// Case ignores differences in upper and lower case.
+	Case bool
+	// Entities decodes HTML entities, so "&amp;" equals "&".
+	Entities bool
+}
+
+// ParseNameNormalization parses a comma separated list of the enabled
+// normalisations, e.g. "whitespace,case,entities".
+func ParseNameNormalization(value string) (NameNormalization, error) {
+	var n NameNormalization
+	for _, part := range strings.Split(value, ",") {
+		switch strings.TrimSpace(part) {
+		case "":
+		case "whitespace":
+			n.Whitespace = true
+		case "case":
+			n.Case = true
+		case "entities":
+			n.Entities = true
+		default:
+			return n, fmt.Errorf("unknown name normalization: %s", part)
+		}
+	}
+	return n, nil
+}
+
+// Options tunes the analysis done by analyzeTreesWith.
+type Options struct {
+	// MixedOECycles additionally checks the graph of L and F parents of OE
+	// items combined for cycles. L and F are independent hierarchies, so
+	// this is off by default.
+	MixedOECycles bool
+	// Names configures the comparison of denormalised names.
+	Names NameNormalization
+	// Severities overrides the default severity of error types. It takes
+	// precedence over the severities of Rules.
+	Severities map[ErrorType]Severity
+	// Rules disables, scopes and tunes the checks by error type name or by
+	// the message of a single check, e.g. "no related FS id".
+	Rules map[string]*Rule
+}
+
+var defaultOptions = Options{
+	Names: NameNormalization{Whitespace: true, Case: true, Entities: true},
+}
@@ -0,0 +1,449 @@
+package orgstruct
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrorType classifies errors; severities and rules are configured by it.
+type ErrorType int
+
+const (
+	MissingReference ErrorType = iota
+	NonExistingReference
+	CycleError
+	InvalidValidity
+	ValidityNotContained
+	ValidityNotCovered
+	VersionOverlap
+	VersionGap
+	DuplicateId
+	CycleLeadIn
+	CycleL
+	CycleF
+	ParentKUMismatch
+	ParentFSMismatch
+	NameMismatch
+	DepthMismatch
+	CycleLeadInL
+	CycleLeadInF
+)
+
+var errorTypeNames = map[ErrorType]string{
+	MissingReference:     "MissingReference",
+	NonExistingReference: "NonExistingReference",
+	CycleError:           "CycleError",
+	InvalidValidity:      "InvalidValidity",
+	ValidityNotContained: "ValidityNotContained",
+	ValidityNotCovered:   "ValidityNotCovered",
+	VersionOverlap:       "VersionOverlap",
+	VersionGap:           "VersionGap",
+	DuplicateId:          "DuplicateId",
+	CycleLeadIn:          "CycleLeadIn",
+	CycleL:               "CycleL",
+	CycleF:               "CycleF",
+	ParentKUMismatch:     "ParentKUMismatch",
+	ParentFSMismatch:     "ParentFSMismatch",
+	NameMismatch:         "NameMismatch",
+	DepthMismatch:        "DepthMismatch",
+	CycleLeadInL:         "CycleLeadInL",
+	CycleLeadInF:         "CycleLeadInF",
+}
+
+func (t ErrorType) String() string {
+	if name, ok := errorTypeNames[t]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+func (t ErrorType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *ErrorType) UnmarshalText(text []byte) error {
+	parsed, err := ParseErrorType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Error is a single problem found on an item.
+type Error struct {
+	Message   string
+	Type      ErrorType
+	Severity  Severity
+	Reference string
+	Detail    string
+}
+
+type customTime struct {
+	time.Time
+}
+
+// FS is the root element of the FS export.
+type FS struct {
+	XMLName xml.Name  `xml:"vw_FS"`
+	Items   []*FSItem `xml:"FS"`
+}
+
+// ItemWithError holds the errors found on an item.
+type ItemWithError struct {
+	Errors []*Error `xml:"-"`
+}
+
+// FSItem is a row of the FS export, linked to its tree by Build.
+type FSItem struct {
+	ItemWithError
+	XMLName   xml.Name   `xml:"FS"`
+	NameShort string     `xml:"FS_KURZ,attr"`
+	NameLong  string     `xml:"FSLANG,attr"`
+	Id        string     `xml:"s_NODE_FS_ID,attr"`
+	Depth     int        `xml:"DEPTH,attr"`
+	ParentId  string     `xml:"s_NODE_PARENT_ID,attr"`
+	Parent    *FSItem    `xml:"-"`
+	Children  []*FSItem  `xml:"-"`
+	OE        []*OEItem  `xml:"-"`
+	Line      int        `xml:"-"`
+	From      customTime `xml:"GAB,attr"`
+	Until     customTime `xml:"GBIS,attr"`
+}
+
+// KU is the root element of the KU export.
+type KU struct {
+	XMLName xml.Name  `xml:"vw_KU"`
+	Items   []*KUItem `xml:"KU"`
+}
+
+// KUItem is a row of the KU export, linked to its tree by Build.
+type KUItem struct {
+	ItemWithError
+	XMLName  xml.Name   `xml:"KU"`
+	NameLong string     `xml:"KULANG,attr"`
+	Id       string     `xml:"s_NODE_KU_ID,attr"`
+	Depth    int        `xml:"DEPTH,attr"`
+	ParentId string     `xml:"s_NODE_PARENT_ID,attr"`
+	Parent   *KUItem    `xml:"-"`
+	Children []*KUItem  `xml:"-"`
+	OE       []*OEItem  `xml:"-"`
+	Line     int        `xml:"-"`
+	From     customTime `xml:"GAB,attr"`
+	Until    customTime `xml:"GBIS,attr"`
+}
+
+// OE is the root element of the OE export.
+type OE struct {
+	XMLName xml.Name  `xml:"OETBL"`
+	Items   []*OEItem `xml:"OE"`
+}
+
+// OEItem is a row of the OE export, linked to its KU, FS and L and F
+// trees by Build.
+type OEItem struct {
+	ItemWithError
+	XMLName      xml.Name   `xml:"OE"`
+	Id           string     `xml:"s_OE_ID,attr"`
+	KUId         string     `xml:"s_KU_ID,attr"`
+	KU           *KUItem    `xml:"-"`
+	FSId         string     `xml:"s_FS_ID,attr"`
+	FS           *FSItem    `xml:"-"`
+	ParentLId    string     `xml:"s_PARENTOE_L_ID,attr"`
+	ParentL      *OEItem    `xml:"-"`
+	LChildren    []*OEItem  `xml:"-"`
+	ParentFId    string     `xml:"s_PARENTOE_F_ID,attr"`
+	ParentF      *OEItem    `xml:"-"`
+	FChildren    []*OEItem  `xml:"-"`
+	Line         int        `xml:"-"`
+	PSId         int        `xml:"PS_OEID,attr"`
+	FSStart      int        `xml:"FS_START,attr"`
+	From         customTime `xml:"Gültig_x0020_ab,attr"`
+	Until        customTime `xml:"Gültig_x0020_bis,attr"`
+	Type         string     `xml:"Typ,attr"`
+	KUName       string     `xml:"Konzernunternehmen,attr"`
+	FSName       string     `xml:"Führungsstruktur,attr"`
+	OrgKZ        string     `xml:"Org-Kz,attr"`
+	OrgName1     string     `xml:"Org-Bez1,attr"`
+	OrgName2     string     `xml:"Org-Bez2,attr"`
+	OrgName3     string     `xml:"Org-Bez3,attr"`
+	Location     string     `xml:"Standort,attr"`
+	CompanyName1 string     `xml:"Firmierung1,attr"`
+	CompanyName2 string     `xml:"Firmierung2,attr"`
+}
+
+func (c *customTime) UnmarshalXMLAttr(attr xml.Attr) error {
+	const shortForm = "2006-01-02T15:04:05"
+	parse, err := time.Parse(shortForm, attr.Value)
+	if err != nil {
+		return fmt.Errorf("invalid date in attribute %s: %v", attr.Name.Local, err)
+	}
+	*c = customTime{parse}
+	return nil
+}
+
+// ParseError describes an XML export that could not be decoded, together
+// with the position in the input at which decoding stopped.
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", file, e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// positionReader keeps track of the line and column of the last byte read.
+// As it implements io.ByteReader, xml.Decoder reads from it byte by byte
+// without buffering, so the position always matches the decoder's.
+type positionReader struct {
+	r      io.ByteReader
+	line   int
+	column int
+}
+
+func newPositionReader(r io.Reader) *positionReader {
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = bufio.NewReader(r)
+	}
+	return &positionReader{r: byteReader, line: 1}
+}
+
+func (p *positionReader) ReadByte() (byte, error) {
+	b, err := p.r.ReadByte()
+	if err != nil {
+		return b, err
+	}
+
+	if b == '\n' {
+		p.line++
+		p.column = 0
+	} else if b&0xC0 != 0x80 {
+		// count characters, not UTF-8 continuation bytes
+		p.column++
+	}
+	return b, nil
+}
+
+func (p *positionReader) Read(data []byte) (int, error) {
+	for i := range data {
+		b, err := p.ReadByte()
+		if err != nil {
+			return i, err
+		}
+		data[i] = b
+	}
+	return len(data), nil
+}
+
+func (p *positionReader) error(err error) *ParseError {
+	return &ParseError{Line: p.line, Column: p.column, Err: err}
+}
+
+// withFile adds the file name to parse errors.
+func withFile(err error, path string) error {
+	if parseError, ok := err.(*ParseError); ok {
+		parseError.File = path
+	}
+	return err
+}
+
+// decodeItems reads an XML export token by token and decodes every element
+// named item below the root element one at a time, handing it to add together
+// with the line it starts on. Other elements below the root are skipped.
+func decodeItems(r io.Reader, root string, item string, add func(*xml.Decoder, *xml.StartElement, int) error) error {
+	reader := newPositionReader(r)
+	decoder := xml.NewDecoder(reader)
+	inRoot := false
+
+	for {
+		// the decoder has already consumed the '<' of the next element
+		line := reader.line
+		token, err := decoder.Token()
+		if err != nil {
+			return reader.error(err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if !inRoot {
+				if element.Name.Local != root {
+					return reader.error(fmt.Errorf("expected element type <%s> but have <%s>", root, element.Name.Local))
+				}
+				inRoot = true
+			} else if element.Name.Local == item {
+				if err := add(decoder, &element, line); err != nil {
+					return reader.error(err)
+				}
+			} else if err := decoder.Skip(); err != nil {
+				return reader.error(err)
+			}
+		case xml.EndElement:
+			// only the end of the root element is not consumed above
+			return nil
+		}
+	}
+}
+
+func parseFS(path string) (map[string][]*FSItem, []*FSItem, error) {
+	xmlFile, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer xmlFile.Close()
+	fsVersions, duplicates, err := parseFSReader(xmlFile)
+	return fsVersions, duplicates, withFile(err, path)
+}
+
+func parseFSBytes(data []byte) (map[string][]*FSItem, []*FSItem, error) {
+	return parseFSReader(bytes.NewReader(data))
+}
+
+// parseFSReader returns all versions of each FS item by id as well as all
+// rows colliding with another row of the same id and validity. Of those, only
+// the first row is kept as a version.
+func parseFSReader(r io.Reader) (map[string][]*FSItem, []*FSItem, error) {
+	fsVersions := make(map[string][]*FSItem)
+	var duplicates []*FSItem
+
+	err := decodeItems(r, "vw_FS", "FS", func(decoder *xml.Decoder, start *xml.StartElement, line int) error {
+		item := &FSItem{Line: line}
+		if err := decoder.DecodeElement(item, start); err != nil {
+			return err
+		}
+
+		for _, kept := range fsVersions[item.Id] {
+			if kept.From.Equal(item.From.Time) && kept.Until.Equal(item.Until.Time) {
+				if addDuplicateIdErrors(&kept.ItemWithError, kept.Line, &item.ItemWithError, item.Line) {
+					duplicates = append(duplicates, kept)
+				}
+				duplicates = append(duplicates, item)
+				return nil
+			}
+		}
+
+		fsVersions[item.Id] = append(fsVersions[item.Id], item)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortFSVersions(fsVersions)
+	return fsVersions, duplicates, nil
+}
+
+func parseKU(path string) (map[string][]*KUItem, []*KUItem, error) {
+	xmlFile, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer xmlFile.Close()
+	kuVersions, duplicates, err := parseKUReader(xmlFile)
+	return kuVersions, duplicates, withFile(err, path)
+}
+
+func parseKUBytes(data []byte) (map[string][]*KUItem, []*KUItem, error) {
+	return parseKUReader(bytes.NewReader(data))
+}
+
+// parseKUReader returns all versions of each KU item by id as well as all
+// rows colliding with another row of the same id and validity. Of those, only
+// the first row is kept as a version.
+func parseKUReader(r io.Reader) (map[string][]*KUItem, []*KUItem, error) {
+	kuVersions := make(map[string][]*KUItem)
+	var duplicates []*KUItem
+
+	err := decodeItems(r, "vw_KU", "KU", func(decoder *xml.Decoder, start *xml.StartElement, line int) error {
+		item := &KUItem{Line: line}
+		if err := decoder.DecodeElement(item, start); err != nil {
+			return err
+		}
+
+		for _, kept := range kuVersions[item.Id] {
+			if kept.From.Equal(item.From.Time) && kept.Until.Equal(item.Until.Time) {
+				if addDuplicateIdErrors(&kept.ItemWithError, kept.Line, &item.ItemWithError, item.Line) {
+					duplicates = append(duplicates, kept)
+				}
+				duplicates = append(duplicates, item)
+				return nil
+			}
+		}
+
+		kuVersions[item.Id] = append(kuVersions[item.Id], item)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortKUVersions(kuVersions)
+	return kuVersions, duplicates, nil
+}
+
+func parseOE(path string) (map[string][]*OEItem, []*OEItem, error) {
+	xmlFile, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer xmlFile.Close()
+	oeVersions, duplicates, err := parseOEReader(xmlFile)
+	return oeVersions, duplicates, withFile(err, path)
+}
+
+func parseOEBytes(data []byte) (map[string][]*OEItem, []*OEItem, error) {
+	return parseOEReader(bytes.NewReader(data))
+}
+
+// parseOEReader returns all versions of each OE item by id as well as all
+// rows colliding with another row of the same id and validity. Of those, only
+// the first row is kept as a version.
+func parseOEReader(r io.Reader) (map[string][]*OEItem, []*OEItem, error) {
+	oeVersions := make(map[string][]*OEItem)
+	var duplicates []*OEItem
+
+	err := decodeItems(r, "OETBL", "OE", func(decoder *xml.Decoder, start *xml.StartElement, line int) error {
+		item := &OEItem{Line: line}
+		if err := decoder.DecodeElement(item, start); err != nil {
+			return err
+		}
+
+		for _, kept := range oeVersions[item.Id] {
+			if kept.From.Equal(item.From.Time) && kept.Until.Equal(item.Until.Time) {
+				if addDuplicateIdErrors(&kept.ItemWithError, kept.Line, &item.ItemWithError, item.Line) {
+					duplicates = append(duplicates, kept)
+				}
+				duplicates = append(duplicates, item)
+				return nil
+			}
+		}
+
+		oeVersions[item.Id] = append(oeVersions[item.Id], item)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortOEVersions(oeVersions)
+	return oeVersions, duplicates, nil
+}
@@ -0,0 +1,372 @@
+package orgstruct
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func parseTime(t string) customTime {
+	tt, _ := time.Parse("2006-01-02T15:04:05", t)
+	return customTime{tt}
+}
+
+func TestParseFS(t *testing.T) {
+	input := `<vw_FS>
+				<FS
+					s_NODE_FS_ID="1E34442D-D6CD-47BE-8C41-FED7F4DD60C9"
+					s_NODE_PARENT_ID="1E34442D-D6CD-47BE-8C41-FED7F4DD60C8"
+					DEPTH="1"
+					GAB="1900-01-01T00:00:00"
+					GBIS="2025-12-31T00:00:00"
+					FS_KURZ="extern"
+					FSLANG="externe Firma" />
+			  </vw_FS>`
+
+	itemMap, duplicates, err := parseFSBytes([]byte(input))
+	if err != nil {
+		t.Errorf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(itemMap) != 1 {
+		t.Errorf("wanted 1 item, got: %d", len(itemMap))
+	}
+
+	if len(duplicates) != 0 {
+		t.Errorf("wanted no duplicates, got: %d", len(duplicates))
+	}
+
+	item := itemMap["1E34442D-D6CD-47BE-8C41-FED7F4DD60C9"][0]
+
+	if item.Id != "1E34442D-D6CD-47BE-8C41-FED7F4DD60C9" {
+		t.Errorf("wanted id 1E34442D-D6CD-47BE-8C41-FED7F4DD60C9, got: %s", item.Id)
+	}
+
+	if item.ParentId != "1E34442D-D6CD-47BE-8C41-FED7F4DD60C8" {
+		t.Errorf("wanted parent id 1E34442D-D6CD-47BE-8C41-FED7F4DD60C8, got: %s", item.ParentId)
+	}
+
+	if item.Depth != 1 {
+		t.Errorf("wanted depth 1 got: %d", item.Depth)
+	}
+
+	if item.From != parseTime("1900-01-01T00:00:00") {
+		t.Errorf("wanted id %s got: %s", parseTime("1900-01-01T00:00:00"), item.From)
+	}
+
+	if item.Until != parseTime("2025-12-31T00:00:00") {
+		t.Errorf("wanted id %s got: %s", parseTime("2025-12-31T00:00:00"), item.From)
+	}
+
+	if item.NameShort != "extern" {
+		t.Errorf("wanted short name extern got: %s", item.NameShort)
+	}
+
+	if item.NameLong != "externe Firma" {
+		t.Errorf("wanted long name externe Firma, got: %s", item.NameLong)
+	}
+}
+
+func TestParseKU(t *testing.T) {
+	input := `<vw_KU>
+				<KU
+					s_NODE_KU_ID="66470697-873F-4BF8-B762-72B028E5951C"
+					s_NODE_PARENT_ID="66470697-873F-4BF8-B762-72B028E5951B"
+					DEPTH="1"
+					GAB="1900-01-01T00:00:00"
+					GBIS="9999-12-31T00:00:00"
+					KULANG="Bundeseisenbahnvermögen" />
+			  </vw_KU>`
+
+	itemMap, duplicates, err := parseKUBytes([]byte(input))
+	if err != nil {
+		t.Errorf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(itemMap) != 1 {
+		t.Errorf("wanted 1 item, got: %d", len(itemMap))
+	}
+
+	if len(duplicates) != 0 {
+		t.Errorf("wanted no duplicates, got: %d", len(duplicates))
+	}
+
+	item := itemMap["66470697-873F-4BF8-B762-72B028E5951C"][0]
+
+	if item.Id != "66470697-873F-4BF8-B762-72B028E5951C" {
+		t.Errorf("wanted id 66470697-873F-4BF8-B762-72B028E5951C, got: %s", item.Id)
+	}
+
+	if item.ParentId != "66470697-873F-4BF8-B762-72B028E5951B" {
+		t.Errorf("wanted parent id 66470697-873F-4BF8-B762-72B028E5951B, got: %s", item.ParentId)
+	}
+
+	if item.Depth != 1 {
+		t.Errorf("wanted depth 1 got: %d", item.Depth)
+	}
+
+	if item.From != parseTime("1900-01-01T00:00:00") {
+		t.Errorf("wanted id %s got: %s", parseTime("1900-01-01T00:00:00"), item.From)
+	}
+
+	if item.Until != parseTime("9999-12-31T00:00:00") {
+		t.Errorf("wanted id %s got: %s", parseTime("9999-12-31T00:00:00"), item.From)
+	}
+
+	if item.NameLong != "Bundeseisenbahnvermögen" {
+		t.Errorf("wanted long name Bundeseisenbahnvermögen got: %s", item.NameLong)
+	}
+}
+
+func TestParseOE(t *testing.T) {
+	input := `<OETBL>
+				<OE
+					s_OE_ID="oe1"
+					s_KU_ID="ku1"
+					s_FS_ID="fs1"
+					s_PARENTOE_L_ID="oe10"
+					s_PARENTOE_F_ID="oe20"
+					PS_OEID="7433"
+					FS_START="0"
+					Gültig_x0020_ab="2002-07-18T00:00:00"
+					Gültig_x0020_bis="9999-12-31T00:00:00"
+					Typ="Regionalbereich"
+					Konzernunternehmen="DB Station&amp;Service AG"
+					Führungsstruktur="Personenbahnhöfe"
+					Org-Kz="I.SV-O"
+					Org-Bez1="Leitung Regionalbereich Ost"
+					Standort="Bln" />
+			  </OETBL>`
+
+	itemMap, duplicates, err := parseOEBytes([]byte(input))
+	if err != nil {
+		t.Errorf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(itemMap) != 1 {
+		t.Errorf("wanted 1 item, got: %d", len(itemMap))
+	}
+
+	if len(duplicates) != 0 {
+		t.Errorf("wanted no duplicates, got: %d", len(duplicates))
+	}
+
+	item := itemMap["oe1"][0]
+
+	if item.Id != "oe1" {
+		t.Errorf("wanted id oe1, got: %s", item.Id)
+	}
+
+	if item.ParentLId != "oe10" {
+		t.Errorf("wanted parent L id oe10, got: %s", item.ParentLId)
+	}
+
+	if item.ParentFId != "oe20" {
+		t.Errorf("wanted parent F id oe20, got: %s", item.ParentFId)
+	}
+
+	if item.KUId != "ku1" {
+		t.Errorf("wanted KU id ku1, got: %s", item.KUId)
+	}
+
+	if item.FSId != "fs1" {
+		t.Errorf("wanted FS id fs1, got: %s", item.FSId)
+	}
+
+	if item.From != parseTime("2002-07-18T00:00:00") {
+		t.Errorf("wanted id %s got: %s", parseTime("2002-07-18T00:00:00"), item.From)
+	}
+
+	if item.Until != parseTime("9999-12-31T00:00:00") {
+		t.Errorf("wanted id %s got: %s", parseTime("9999-12-31T00:00:00"), item.From)
+	}
+
+	if item.Type != "Regionalbereich" {
+		t.Errorf("wanted type Regionalbereich, got: %s", item.Type)
+	}
+
+	if item.KUName != "DB Station&Service AG" {
+		t.Errorf("wanted KU name DB Station&Service AG, got: %s", item.KUName)
+	}
+
+	if item.FSName != "Personenbahnhöfe" {
+		t.Errorf("wanted FS name Personenbahnhöfe, got: %s", item.FSName)
+	}
+
+	if item.OrgKZ != "I.SV-O" {
+		t.Errorf("wanted org KZ I.SV-O, got: %s", item.OrgKZ)
+	}
+
+	// to be continued ;-)
+}
+
+func assertParseError(err error, line int, column int, t *testing.T) {
+	if err == nil {
+		t.Fatalf("wanted parsing error, got none")
+	}
+
+	parseError, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("wanted *ParseError, got: %T (%s)", err, err)
+	}
+
+	if parseError.Line != line || parseError.Column != column {
+		t.Errorf("wanted error at %d:%d, got: %d:%d (%s)", line, column, parseError.Line, parseError.Column, err)
+	}
+}
+
+func TestParseOETruncated(t *testing.T) {
+	input := `<OETBL>
+				<OE s_OE_ID="oe1" Gültig_x0020_ab="2002-07-18T00:00:00" />
+				<OE s_OE_ID="oe2" Gült`
+
+	itemMap, duplicates, err := parseOEBytes([]byte(input))
+	assertParseError(err, 3, 26, t)
+
+	if itemMap != nil || duplicates != nil {
+		t.Errorf("wanted no items, got: %d", len(itemMap))
+	}
+}
+
+func TestParseOEInvalidDate(t *testing.T) {
+	input := `<OETBL>
+				<OE s_OE_ID="oe1" Gültig_x0020_ab="18.07.2002" />
+			  </OETBL>`
+
+	_, _, err := parseOEBytes([]byte(input))
+	assertParseError(err, 2, 53, t)
+}
+
+func TestParseKUWrongRoot(t *testing.T) {
+	input := `<vw_FS>
+				<FS s_NODE_FS_ID="fs1" />
+			  </vw_FS>`
+
+	_, _, err := parseKUBytes([]byte(input))
+	assertParseError(err, 1, 7, t)
+}
+
+func TestParseFSEmpty(t *testing.T) {
+	_, _, err := parseFSBytes([]byte{})
+	assertParseError(err, 1, 0, t)
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	err := withFile(&ParseError{Line: 3, Column: 7, Err: io.ErrUnexpectedEOF}, "XML_OE.xml")
+	if err.Error() != "XML_OE.xml:3:7: unexpected EOF" {
+		t.Errorf("wanted file position in message, got: %s", err)
+	}
+}
+
+func TestParseOESkipsUnknownElements(t *testing.T) {
+	input := `<?xml version="1.0" encoding="utf-8"?>
+			  <OETBL>
+				<META><OE s_OE_ID="nested" /></META>
+				<OE s_OE_ID="oe1" />
+				<OE s_OE_ID="oe2"></OE>
+			  </OETBL>`
+
+	itemMap, _, err := parseOEBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(itemMap) != 2 || itemMap["oe1"] == nil || itemMap["oe2"] == nil {
+		t.Errorf("wanted items oe1 and oe2, got: %d items", len(itemMap))
+	}
+}
+
+func generateOEExport(count int) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("<OETBL>\n")
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&buffer, `<OE s_OE_ID="oe%d" s_KU_ID="ku1" s_FS_ID="fs1" s_PARENTOE_L_ID="oe%d" s_PARENTOE_F_ID="oe%d" PS_OEID="%d" FS_START="0" Gültig_x0020_ab="2002-07-18T00:00:00" Gültig_x0020_bis="9999-12-31T00:00:00" Typ="Abteilung" Konzernunternehmen="DB Station&amp;Service AG" Führungsstruktur="Personenbahnhöfe" Org-Kz="I.SV-O %d" Org-Bez1="Leitung Regionalbereich Ost" Standort="Bln" />`+"\n", i, i/2, i/3, i, i)
+	}
+	buffer.WriteString("</OETBL>\n")
+	return buffer.Bytes()
+}
+
+// unmarshalOE is the former implementation of parseOE, which reads the whole
+// export into memory before decoding it. It is kept as a baseline for the
+// benchmarks.
+func unmarshalOE(r io.Reader) ([]*OEItem, map[string]*OEItem, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var oe OE
+	if err := xml.Unmarshal(data, &oe); err != nil {
+		return nil, nil, err
+	}
+
+	oeMap := make(map[string]*OEItem)
+	for _, item := range oe.Items {
+		oeMap[item.Id] = item
+	}
+
+	return oe.Items, oeMap, nil
+}
+
+func TestParseOEReaderMatchesUnmarshal(t *testing.T) {
+	data := generateOEExport(100)
+
+	expectedList, _, err := unmarshalOE(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("wanted no unmarshal error, got: %s", err)
+	}
+
+	itemMap, _, err := parseOEReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(itemMap) != len(expectedList) {
+		t.Fatalf("wanted %d items, got: %d", len(expectedList), len(itemMap))
+	}
+
+	for i, expected := range expectedList {
+		item := itemMap[expected.Id][0]
+		if item.Line != i+2 {
+			t.Errorf("wanted item %d at line %d, got: %d", i, i+2, item.Line)
+		}
+
+		item.Line = 0
+		if !reflect.DeepEqual(expected, item) {
+			t.Errorf("wanted item %d to be %+v, got: %+v", i, expected, item)
+		}
+	}
+}
+
+const benchmarkOECount = 20000
+
+func BenchmarkParseOEUnmarshal(b *testing.B) {
+	data := generateOEExport(benchmarkOECount)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := unmarshalOE(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseOEReader(b *testing.B) {
+	data := generateOEExport(benchmarkOECount)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := parseOEReader(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package orgstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Query selects OE items. Empty fields match every item, the others have
+// to match all. Comparisons ignore case.
+type Query struct {
+	// OrgKZ matches the Org-Kz exactly or, if it ends with "*", as prefix.
+	OrgKZ string
+	// Name matches a part of Org-Bez1, Org-Bez2 or Org-Bez3.
+	Name string
+	// Location matches the Standort exactly.
+	Location string
+	// Type matches the Typ exactly.
+	Type string
+	// KUId and FSId match the id of the related KU and FS.
+	KUId string
+	FSId string
+}
+
+func (q Query) matches(item *OEItem) bool {
+	if q.OrgKZ != "" {
+		if strings.HasSuffix(q.OrgKZ, "*") {
+			prefix := strings.ToLower(strings.TrimSuffix(q.OrgKZ, "*"))
+			if !strings.HasPrefix(strings.ToLower(item.OrgKZ), prefix) {
+				return false
+			}
+		} else if !strings.EqualFold(item.OrgKZ, q.OrgKZ) {
+			return false
+		}
+	}
+
+	if q.Name != "" {
+		name := strings.ToLower(q.Name)
+		if !strings.Contains(strings.ToLower(item.OrgName1), name) &&
+			!strings.Contains(strings.ToLower(item.OrgName2), name) &&
+			!strings.Contains(strings.ToLower(item.OrgName3), name) {
+			return false
+		}
+	}
+
+	if q.Location != "" && !strings.EqualFold(item.Location, q.Location) {
+		return false
+	}
+	if q.Type != "" && !strings.EqualFold(item.Type, q.Type) {
+		return false
+	}
+	if q.KUId != "" && !strings.EqualFold(item.KUId, q.KUId) {
+		return false
+	}
+	if q.FSId != "" && !strings.EqualFold(item.FSId, q.FSId) {
+		return false
+	}
+	return true
+}
+
+// QueryPathItem is an OE on the path of a query result to its root.
+type QueryPathItem struct {
+	Id    string `json:"id"`
+	OrgKZ string `json:"orgKZ"`
+}
+
+func (p QueryPathItem) String() string {
+	if p.OrgKZ == "" {
+		return p.Id
+	}
+	return p.OrgKZ
+}
+
+// QueryError is an error of an OE found by a query.
+type QueryError struct {
+	Type     ErrorType `json:"type"`
+	Severity Severity  `json:"severity"`
+	Message  string    `json:"message"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// QueryResult describes an OE found by a query. The paths start at the root
+// and end with the OE itself.
+type QueryResult struct {
+	Id       string          `json:"id"`
+	OrgKZ    string          `json:"orgKZ"`
+	Names    []string        `json:"names"`
+	Type     string          `json:"type"`
+	Location string          `json:"location"`
+	KUId     string          `json:"kuId"`
+	KUName   string          `json:"kuName"`
+	FSId     string          `json:"fsId"`
+	FSName   string          `json:"fsName"`
+	PathL    []QueryPathItem `json:"pathL"`
+	PathF    []QueryPathItem `json:"pathF"`
+	Errors   []*QueryError   `json:"errors"`
+}
+
+func queryPath(path []*OEItem) []QueryPathItem {
+	items := make([]QueryPathItem, len(path))
+	for i, item := range path {
+		items[len(path)-1-i] = QueryPathItem{Id: item.Id, OrgKZ: item.OrgKZ}
+	}
+	return items
+}
+
+// RunQuery returns the OE items matching the query ordered by Org-Kz and id.
+// Build has to run before, and Analyze as well to include errors.
+func (m *Model) RunQuery(q Query) []*QueryResult {
+	results := []*QueryResult{}
+	for _, item := range m.OE {
+		if !q.matches(item) {
+			continue
+		}
+
+		r := &QueryResult{
+			Id:       item.Id,
+			OrgKZ:    item.OrgKZ,
+			Names:    []string{},
+			Type:     item.Type,
+			Location: item.Location,
+			KUId:     item.KUId,
+			FSId:     item.FSId,
+			PathL:    queryPath(item.PathToRootL()),
+			PathF:    queryPath(item.PathToRootF()),
+			Errors:   []*QueryError{},
+		}
+		for _, name := range []string{item.OrgName1, item.OrgName2, item.OrgName3} {
+			if name != "" {
+				r.Names = append(r.Names, name)
+			}
+		}
+		if item.KU != nil {
+			r.KUName = item.KU.NameLong
+		}
+		if item.FS != nil {
+			r.FSName = item.FS.NameLong
+		}
+		for _, e := range item.Errors {
+			r.Errors = append(r.Errors, &QueryError{Type: e.Type, Severity: e.Severity, Message: e.Message, Detail: e.Detail})
+		}
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].OrgKZ != results[j].OrgKZ {
+			return results[i].OrgKZ < results[j].OrgKZ
+		}
+		return results[i].Id < results[j].Id
+	})
+	return results
+}
+
+func joinPath(path []QueryPathItem) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, " > ")
+}
+
+func withId(name string, id string) string {
+	if id == "" {
+		return ""
+	}
+	if name == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", name, id)
+}
+
+// WriteQueryTable writes the results as a table with one row per OE.
+func WriteQueryTable(w io.Writer, results []*QueryResult) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tORG-KZ\tTYP\tSTANDORT\tNAME\tKU\tFS\tPATH L\tPATH F\tERRORS")
+	for _, r := range results {
+		messages := make([]string, len(r.Errors))
+		for i, e := range r.Errors {
+			messages[i] = e.Message
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Id, r.OrgKZ, r.Type, r.Location, strings.Join(r.Names, " / "),
+			withId(r.KUName, r.KUId), withId(r.FSName, r.FSId),
+			joinPath(r.PathL), joinPath(r.PathF), strings.Join(messages, "; "))
+	}
+	return table.Flush()
+}
+
+// WriteJSONQuery writes the results as indented JSON.
+func WriteJSONQuery(w io.Writer, results []*QueryResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
@@ -0,0 +1,73 @@
+package orgstruct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func queryModel() *Model {
+	m := &Model{
+		OE: map[string]*OEItem{
+			"oe1": {Id: "oe1", KUId: "ku1", FSId: "fs1", OrgKZ: "I.SV-O", OrgName1: "Leitung", Location: "Bln", Type: "Regionalbereich"},
+			"oe2": {Id: "oe2", KUId: "ku1", FSId: "fs1", ParentLId: "oe1", ParentFId: "oe1", OrgKZ: "I.SV-O 1", OrgName2: "Bahnhof Berlin", Location: "Bln", Type: "Abteilung"},
+			"oe3": {Id: "oe3", KUId: "ku1", ParentLId: "oe2", OrgKZ: "I.SV-W", OrgName1: "Bahnhof Köln", Location: "K", Type: "Abteilung"},
+		},
+		KU: map[string]*KUItem{"ku1": {Id: "ku1", NameLong: "Station&Service"}},
+		FS: map[string]*FSItem{"fs1": {Id: "fs1", NameLong: "Personenbahnhöfe"}},
+	}
+	m.Analyze(DefaultOptions())
+	return m
+}
+
+func TestRunQuery(t *testing.T) {
+	m := queryModel()
+
+	tests := []struct {
+		query    Query
+		expected []string
+	}{
+		{Query{}, []string{"oe1", "oe2", "oe3"}},
+		{Query{OrgKZ: "i.sv-o"}, []string{"oe1"}},
+		{Query{OrgKZ: "I.SV-O*"}, []string{"oe1", "oe2"}},
+		{Query{Name: "bahnhof"}, []string{"oe2", "oe3"}},
+		{Query{Location: "bln", Type: "Abteilung"}, []string{"oe2"}},
+		{Query{KUId: "ku1", FSId: "fs1"}, []string{"oe1", "oe2"}},
+		{Query{KUId: "ku2"}, []string{}},
+	}
+
+	for _, test := range tests {
+		results := m.RunQuery(test.query)
+		ids := make([]string, len(results))
+		for i, r := range results {
+			ids[i] = r.Id
+		}
+		assertIds(t, test.expected, ids)
+	}
+}
+
+func TestRunQueryResult(t *testing.T) {
+	results := queryModel().RunQuery(Query{OrgKZ: "I.SV-W"})
+	if len(results) != 1 {
+		t.Fatalf("wanted 1 result, got: %d", len(results))
+	}
+	r := results[0]
+
+	if r.KUName != "Station&Service" || r.FSName != "" || joinPath(r.PathL) != "I.SV-O > I.SV-O 1 > I.SV-W" || joinPath(r.PathF) != "I.SV-W" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	if len(r.Errors) != 1 || r.Errors[0].Message != NO_RELATED_FS_ID || r.Errors[0].Severity != SeverityError {
+		t.Errorf("wanted the missing FS, got: %d errors", len(r.Errors))
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteQueryTable(&buffer, results); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID ") || !strings.Contains(lines[1], "Station&Service (ku1)") {
+		t.Errorf("unexpected table:\n%s", buffer.String())
+	}
+}
@@ -0,0 +1,89 @@
+package orgstruct
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ReportFinding is a finding as written to the JSON report and findings diff.
+type ReportFinding struct {
+	Kind      string    `json:"kind"`
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Line      int       `json:"line,omitempty"`
+	Type      ErrorType `json:"type"`
+	Severity  Severity  `json:"severity"`
+	Message   string    `json:"message"`
+	Reference string    `json:"reference,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+type reportTotals struct {
+	OE      int `json:"OE"`
+	KU      int `json:"KU"`
+	FS      int `json:"FS"`
+	Error   int `json:"error"`
+	Warning int `json:"warning"`
+	Info    int `json:"info"`
+	Total   int `json:"total"`
+}
+
+type reportCycle struct {
+	Kind      string   `json:"kind"`
+	Hierarchy string   `json:"hierarchy,omitempty"`
+	Ids       []string `json:"ids"`
+	Names     []string `json:"names"`
+}
+
+type report struct {
+	Findings []*ReportFinding `json:"findings"`
+	Cycles   []*reportCycle   `json:"cycles"`
+	Fixed    []*BaselineEntry `json:"fixed,omitempty"`
+	Totals   reportTotals     `json:"totals"`
+}
+
+func newReportFinding(f *Finding) *ReportFinding {
+	return &ReportFinding{
+		Kind:      f.Kind,
+		Id:        f.Id,
+		Name:      f.Name,
+		Line:      f.Line,
+		Type:      f.Type,
+		Severity:  f.Severity,
+		Message:   f.Message,
+		Reference: f.Reference,
+		Detail:    f.Detail,
+	}
+}
+
+func buildReport(errors *Errors) *report {
+	r := &report{Findings: []*ReportFinding{}, Cycles: []*reportCycle{}}
+
+	for _, f := range errors.Findings() {
+		r.Findings = append(r.Findings, newReportFinding(f))
+	}
+
+	for _, c := range errors.Cycles {
+		r.Cycles = append(r.Cycles, &reportCycle{Kind: c.Kind, Hierarchy: c.Hierarchy, Ids: c.Ids, Names: c.Names})
+	}
+
+	r.Fixed = errors.Fixed
+
+	r.Totals.OE = len(errors.OEErrors)
+	r.Totals.KU = len(errors.KUErrors)
+	r.Totals.FS = len(errors.FSErrors)
+	counts := errors.CountBySeverity()
+	r.Totals.Error = counts[SeverityError]
+	r.Totals.Warning = counts[SeverityWarning]
+	r.Totals.Info = counts[SeverityInfo]
+	r.Totals.Total = errors.Count()
+	return r
+}
+
+// WriteJSONReport writes the findings, cycles, fixed baseline entries and
+// totals as indented JSON.
+func WriteJSONReport(w io.Writer, errors *Errors) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildReport(errors))
+}
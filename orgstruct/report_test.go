@@ -0,0 +1,70 @@
+package orgstruct
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestReportFromErrors(t *testing.T) {
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	oeMap := make(map[string]*OEItem)
+
+	kuMap["ku1"] = &KUItem{Id: "ku1", ParentId: "ku10", NameLong: "Konzern"}
+	oeMap["oe1"] = &OEItem{Id: "oe1", OrgKZ: "I.SV-O"}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	r := buildReport(result)
+	if r.Totals.OE != 2 || r.Totals.KU != 1 || r.Totals.FS != 0 || r.Totals.Total != 3 {
+		t.Errorf("wanted totals 2/1/0/3, got: %+v", r.Totals)
+	}
+
+	var found bool
+	for _, f := range r.Findings {
+		if f.Kind == KindKU && f.Id == "ku1" {
+			found = true
+			if f.Name != "Konzern" || f.Type != NonExistingReference || f.Reference != "ku10" {
+				t.Errorf("unexpected KU finding: %+v", f)
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("wanted finding for ku1, got none")
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1", OrgKZ: "I.SV-O"}}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := WriteJSONReport(&buffer, result); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	var decoded struct {
+		Findings []struct {
+			Kind string `json:"kind"`
+			Type string `json:"type"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatalf("wanted valid JSON, got: %s", err)
+	}
+
+	if len(decoded.Findings) != 2 {
+		t.Fatalf("wanted 2 findings, got: %d", len(decoded.Findings))
+	}
+
+	if decoded.Findings[0].Kind != "OE" || decoded.Findings[0].Type != "MissingReference" {
+		t.Errorf("unexpected finding: %+v", decoded.Findings[0])
+	}
+}
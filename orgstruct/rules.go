@@ -0,0 +1,245 @@
+package orgstruct
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Scope selects the items a rule applies to. Every non-empty list has to
+// match; a list matches if any of its values does.
+type Scope struct {
+	// Kinds lists entity kinds: OE, KU or FS.
+	Kinds []string `json:"kinds"`
+	// Types lists values of the Typ attribute of OE items.
+	Types []string `json:"types"`
+	// KUSubtrees lists ids of KU items. An item matches if it is or
+	// belongs to one of these KU or one of their descendants.
+	KUSubtrees []string `json:"kuSubtrees"`
+	// FSSubtrees lists ids of FS items, like KUSubtrees.
+	FSSubtrees []string `json:"fsSubtrees"`
+}
+
+// Rule configures the checks reporting errors of one type, or the single
+// check reporting errors with one message.
+type Rule struct {
+	// Enabled disables the rule if false.
+	Enabled *bool `json:"enabled"`
+	// Severity overrides the default severity.
+	Severity *Severity `json:"severity"`
+	// Scope restricts the rule to the matching items.
+	Scope *Scope `json:"scope"`
+	// Exclude exempts the matching items from the rule.
+	Exclude *Scope `json:"exclude"`
+}
+
+// Config is the content of a rule configuration file. Rules are keyed by
+// error type or by the message of a single check, e.g.
+//
+//	{"rules": {"DepthMismatch": {"enabled": false},
+//	           "NameMismatch": {"severity": "info", "exclude": {"types": ["Stab"]}},
+//	           "no related FS id": {"exclude": {"types": ["Stab"]}}}}
+type Config struct {
+	Rules map[string]*Rule `json:"rules"`
+}
+
+// checkMessages lists the messages of the single checks that rules can be
+// keyed by.
+var checkMessages = []string{
+	NON_EXISTING_PARENT, NON_EXISTING_PARENT_L, NON_EXISTING_PARENT_F,
+	NO_RELATED_KU_ID, NON_EXISTING_RELATED_KU,
+	NO_RELATED_FS_ID, NON_EXISTING_RELATED_FS,
+	CYCLE_REFERENCE, LEADS_INTO_CYCLE,
+	CYCLE_REFERENCE_L, LEADS_INTO_CYCLE_L,
+	CYCLE_REFERENCE_F, LEADS_INTO_CYCLE_F,
+	INVALID_VALIDITY,
+	VALIDITY_OUTSIDE_PARENT, VALIDITY_OUTSIDE_PARENT_L, VALIDITY_OUTSIDE_PARENT_F,
+	VALIDITY_OUTSIDE_RELATED_KU, VALIDITY_OUTSIDE_RELATED_FS,
+	PARENT_L_KU_MISMATCH, PARENT_F_FS_MISMATCH,
+	DEPTH_MISMATCH, ROOT_DEPTH,
+	KU_NAME_MISMATCH, FS_NAME_MISMATCH,
+	VERSION_OVERLAP, VERSION_GAP,
+	DUPLICATE_ID,
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// ParseConfig reads a rule configuration and returns the rules by error type
+// name or check message.
+func ParseConfig(r io.Reader) (map[string]*Rule, error) {
+	var config Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid rule configuration: %v", err)
+	}
+
+	rules := make(map[string]*Rule)
+	for name, rule := range config.Rules {
+		if _, err := ParseErrorType(name); err != nil && !containsString(checkMessages, name) {
+			return nil, fmt.Errorf("unknown error type or check: %s", name)
+		}
+		if rule == nil {
+			rule = &Rule{}
+		}
+		for _, scope := range []*Scope{rule.Scope, rule.Exclude} {
+			if scope == nil {
+				continue
+			}
+			for _, kind := range scope.Kinds {
+				if kind != KindOE && kind != KindKU && kind != KindFS {
+					return nil, fmt.Errorf("unknown kind in rule %s: %s", name, kind)
+				}
+			}
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+// LoadConfig reads a rule configuration file, see ParseConfig.
+func LoadConfig(path string) (map[string]*Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules, err := ParseConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return rules, nil
+}
+
+// ruleSubject describes an item with an error for matching it against scopes.
+type ruleSubject struct {
+	kind string
+	typ  string
+	ku   *KUItem
+	fs   *FSItem
+}
+
+func kuSubject(item *KUItem) ruleSubject {
+	return ruleSubject{kind: KindKU, ku: item}
+}
+
+func fsSubject(item *FSItem) ruleSubject {
+	return ruleSubject{kind: KindFS, fs: item}
+}
+
+func oeSubject(item *OEItem) ruleSubject {
+	return ruleSubject{kind: KindOE, typ: item.Type, ku: item.KU, fs: item.FS}
+}
+
+// isInKUSubtree reports whether item or one of its parents has the given id.
+// It stops at cycles.
+func isInKUSubtree(id string, item *KUItem) bool {
+	visited := make(map[*KUItem]bool)
+	for ; item != nil && !visited[item]; item = item.Parent {
+		if item.Id == id {
+			return true
+		}
+		visited[item] = true
+	}
+	return false
+}
+
+// isInFSSubtree reports whether item or one of its parents has the given id.
+// It stops at cycles.
+func isInFSSubtree(id string, item *FSItem) bool {
+	visited := make(map[*FSItem]bool)
+	for ; item != nil && !visited[item]; item = item.Parent {
+		if item.Id == id {
+			return true
+		}
+		visited[item] = true
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (scope *Scope) matches(subject ruleSubject) bool {
+	if len(scope.Kinds) > 0 && !containsString(scope.Kinds, subject.kind) {
+		return false
+	}
+	if len(scope.Types) > 0 && (subject.kind != KindOE || !containsString(scope.Types, subject.typ)) {
+		return false
+	}
+	if len(scope.KUSubtrees) > 0 {
+		var found bool
+		for _, id := range scope.KUSubtrees {
+			found = found || isInKUSubtree(id, subject.ku)
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(scope.FSSubtrees) > 0 {
+		var found bool
+		for _, id := range scope.FSSubtrees {
+			found = found || isInFSSubtree(id, subject.fs)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// rulesFor returns the rules configured for an error, the one for its
+// message before the one for its type.
+func (errors *Errors) rulesFor(e *Error) []*Rule {
+	var rules []*Rule
+	if rule, ok := errors.rules[e.Message]; ok {
+		rules = append(rules, rule)
+	}
+	if rule, ok := errors.rules[e.Type.String()]; ok {
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// applies reports whether the rules for an error apply to the subject.
+// Errors without a rule always apply.
+func (errors *Errors) applies(e *Error, subject ruleSubject) bool {
+	for _, rule := range errors.rulesFor(e) {
+		if rule.Enabled != nil && !*rule.Enabled {
+			return false
+		}
+		if rule.Scope != nil && !rule.Scope.matches(subject) {
+			return false
+		}
+		if rule.Exclude != nil && rule.Exclude.matches(subject) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeError drops an error that was attached to the item but is not
+// reported because of the rule configuration.
+func (item *ItemWithError) removeError(e *Error) {
+	for i, other := range item.Errors {
+		if other == e {
+			item.Errors = append(item.Errors[:i], item.Errors[i+1:]...)
+			return
+		}
+	}
+}
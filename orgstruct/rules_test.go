@@ -0,0 +1,153 @@
+package orgstruct
+
+import (
+	"strings"
+	"testing"
+)
+
+func analyzeWithConfig(t *testing.T, config string, oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) *Errors {
+	rules, err := ParseConfig(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	options := defaultOptions
+	options.Rules = rules
+	buildTrees(oeMap, kuMap, fsMap)
+	return analyzeTreesWith(options, oeMap, kuMap, fsMap)
+}
+
+func TestParseConfig(t *testing.T) {
+	rules, err := ParseConfig(strings.NewReader(`{"rules": {"DepthMismatch": {"enabled": false, "severity": "info"}}}`))
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	rule := rules["DepthMismatch"]
+	if rule == nil || rule.Enabled == nil || *rule.Enabled || rule.Severity == nil || *rule.Severity != SeverityInfo {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+
+	for _, config := range []string{
+		`{"rules": {"NoSuchRule": {}}}`,
+		`{"rules": {"no such check": {}}}`,
+		`{"rules": {"NO_RELATED_FS_ID": {}}}`,
+		`{"rules": {"no parent id": {}}}`,
+		`{"rules": {"DepthMismatch": {"severity": "fatal"}}}`,
+		`{"rules": {"DepthMismatch": {"scope": {"kinds": ["XY"]}}}}`,
+		`{"rulez": {}}`,
+	} {
+		if _, err := ParseConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("wanted error for %s, got none", config)
+		}
+	}
+}
+
+func TestRuleDisabled(t *testing.T) {
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1"}}
+	result := analyzeWithConfig(t, `{"rules": {"MissingReference": {"enabled": false}}}`, oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+
+	if result.Count() != 0 {
+		t.Errorf("wanted no errors, got: %d", result.Count())
+	}
+
+	if len(oeMap["oe1"].Errors) != 0 {
+		t.Errorf("wanted no errors attached to oe1, got: %d", len(oeMap["oe1"].Errors))
+	}
+}
+
+func TestRuleSeverity(t *testing.T) {
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1"}}
+	result := analyzeWithConfig(t, `{"rules": {"MissingReference": {"severity": "warning"}}}`, oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+
+	for _, f := range result.Findings() {
+		if f.Severity != SeverityWarning {
+			t.Errorf("wanted warning, got: %s", f.Severity)
+		}
+	}
+}
+
+func TestRuleScope(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1"},
+		"ku2": {Id: "ku2", ParentId: "ku1", Depth: 1},
+		"ku3": {Id: "ku3"},
+	}
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", KUId: "ku2", Type: "Stab"},
+		"oe2": {Id: "oe2", KUId: "ku3", Type: "Stab"},
+		"oe3": {Id: "oe3", KUId: "ku2", Type: "Linie"},
+	}
+	config := `{"rules": {"MissingReference": {"scope": {"kuSubtrees": ["ku1"]}, "exclude": {"types": ["Linie"]}}}}`
+	result := analyzeWithConfig(t, config, oeMap, kuMap, map[string]*FSItem{})
+
+	assertErrorCount(1, oeMap["oe1"].Errors, t)
+	assertError(Error{Message: NO_RELATED_FS_ID, Type: MissingReference}, oeMap["oe1"].Errors, t)
+	assertErrorCount(0, oeMap["oe2"].Errors, t)
+	assertErrorCount(0, oeMap["oe3"].Errors, t)
+
+	if result.Count() != 1 {
+		t.Errorf("wanted 1 error, got: %d", result.Count())
+	}
+}
+
+func TestRuleScopeKind(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1", ParentId: "ku10"}}
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1", KUId: "ku10", FSId: "fs1"}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1"}}
+	result := analyzeWithConfig(t, `{"rules": {"NonExistingReference": {"scope": {"kinds": ["KU"]}}}}`, oeMap, kuMap, fsMap)
+
+	if len(result.KUErrors) != 1 || len(result.OEErrors) != 0 {
+		t.Errorf("wanted only the KU error, got: %d KU, %d OE", len(result.KUErrors), len(result.OEErrors))
+	}
+}
+
+func TestRuleCheck(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1"}}
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", KUId: "ku1", Type: "Stab"},
+		"oe2": {Id: "oe2", KUId: "ku1", Type: "Linie"},
+		"oe3": {Id: "oe3", Type: "Stab"},
+	}
+	config := `{"rules": {"no related FS id": {"exclude": {"types": ["Stab"]}}}}`
+	result := analyzeWithConfig(t, config, oeMap, kuMap, map[string]*FSItem{})
+
+	assertErrorCount(0, oeMap["oe1"].Errors, t)
+	assertErrorCount(1, oeMap["oe2"].Errors, t)
+	assertError(Error{Message: NO_RELATED_FS_ID, Type: MissingReference}, oeMap["oe2"].Errors, t)
+	assertErrorCount(1, oeMap["oe3"].Errors, t)
+	assertError(Error{Message: NO_RELATED_KU_ID, Type: MissingReference}, oeMap["oe3"].Errors, t)
+
+	if result.Count() != 2 {
+		t.Errorf("wanted 2 errors, got: %d", result.Count())
+	}
+}
+
+func TestRuleCheckSeverity(t *testing.T) {
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1"}}
+	config := `{"rules": {"MissingReference": {"severity": "warning"}, "no related FS id": {"severity": "info"}}}`
+	analyzeWithConfig(t, config, oeMap, map[string]*KUItem{}, map[string]*FSItem{})
+
+	for _, e := range oeMap["oe1"].Errors {
+		want := SeverityWarning
+		if e.Message == NO_RELATED_FS_ID {
+			want = SeverityInfo
+		}
+		if e.Severity != want {
+			t.Errorf("wanted %s for %s, got: %s", want, e.Message, e.Severity)
+		}
+	}
+}
+
+func TestRuleDisabledCycle(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1", ParentId: "ku2"},
+		"ku2": {Id: "ku2", ParentId: "ku1"},
+	}
+	result := analyzeWithConfig(t, `{"rules": {"CycleError": {"enabled": false}}}`, map[string]*OEItem{}, kuMap, map[string]*FSItem{})
+
+	if len(result.Cycles) != 0 {
+		t.Errorf("wanted no cycles, got: %d", len(result.Cycles))
+	}
+	assertErrorCount(0, kuMap["ku1"].Errors, t)
+}
@@ -0,0 +1,118 @@
+package orgstruct
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity tells how serious a finding is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity parses info, warning or error.
+func ParseSeverity(value string) (Severity, error) {
+	for s, name := range severityNames {
+		if name == value {
+			return s, nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity: %s", value)
+}
+
+// ParseErrorType parses the name of an error type, e.g. NameMismatch.
+func ParseErrorType(value string) (ErrorType, error) {
+	for t, name := range errorTypeNames {
+		if name == value {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown error type: %s", value)
+}
+
+// defaultSeverities holds the severity of each error type unless overridden.
+// Error types missing here are errors.
+var defaultSeverities = map[ErrorType]Severity{
+	CycleLeadIn:   SeverityWarning,
+	CycleLeadInL:  SeverityWarning,
+	CycleLeadInF:  SeverityWarning,
+	VersionGap:    SeverityWarning,
+	NameMismatch:  SeverityWarning,
+	DepthMismatch: SeverityWarning,
+}
+
+func defaultSeverity(t ErrorType) Severity {
+	if s, ok := defaultSeverities[t]; ok {
+		return s
+	}
+	return SeverityError
+}
+
+// ParseSeverities parses a comma separated list of severity overrides, e.g.
+// "NameMismatch=info,VersionGap=error".
+func ParseSeverities(value string) (map[ErrorType]Severity, error) {
+	severities := make(map[ErrorType]Severity)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		i := strings.Index(part, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid severity override: %s", part)
+		}
+
+		t, err := ParseErrorType(part[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := ParseSeverity(part[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		severities[t] = s
+	}
+	return severities, nil
+}
+
+// CountBySeverity returns the number of errors of each severity.
+func (errors *Errors) CountBySeverity() map[Severity]int {
+	counts := map[Severity]int{SeverityInfo: 0, SeverityWarning: 0, SeverityError: 0}
+	for _, f := range errors.Findings() {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// Fails reports whether there is an error of at least the given severity.
+func (errors *Errors) Fails(threshold Severity) bool {
+	for _, f := range errors.Findings() {
+		if f.Severity >= threshold {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestParseSeverities(t *testing.T) {
+	severities, err := ParseSeverities("NameMismatch=info, VersionGap=error")
+	if err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	if len(severities) != 2 || severities[NameMismatch] != SeverityInfo || severities[VersionGap] != SeverityError {
+		t.Errorf("unexpected severities: %v", severities)
+	}
+
+	for _, value := range []string{"NameMismatch", "Unknown=info", "NameMismatch=fatal"} {
+		if _, err := ParseSeverities(value); err == nil {
+			t.Errorf("wanted error for %s", value)
+		}
+	}
+}
+
+func TestSeverities(t *testing.T) {
+	kuMap := map[string]*KUItem{"ku1": {Id: "ku1", NameLong: "Konzern", Depth: 1}}
+	fsMap := map[string]*FSItem{"fs1": {Id: "fs1", NameLong: "Vorstand"}}
+	oeMap := map[string]*OEItem{"oe1": {Id: "oe1", KUId: "ku1", FSId: "fs2", KUName: "Tochter"}}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	result := analyzeTrees(oeMap, kuMap, fsMap)
+
+	counts := result.CountBySeverity()
+	if counts[SeverityError] != 1 || counts[SeverityWarning] != 2 || counts[SeverityInfo] != 0 {
+		t.Errorf("wanted 1 error and 2 warnings, got: %v", counts)
+	}
+
+	if !result.Fails(SeverityError) || !result.Fails(SeverityWarning) {
+		t.Errorf("wanted result to fail on errors and warnings")
+	}
+
+	for _, item := range oeMap {
+		item.Errors = nil
+	}
+	for _, item := range kuMap {
+		item.Errors = nil
+	}
+
+	options := defaultOptions
+	options.Severities = map[ErrorType]Severity{NonExistingReference: SeverityInfo, DepthMismatch: SeverityInfo}
+	result = analyzeTreesWith(options, oeMap, kuMap, fsMap)
+
+	if result.Fails(SeverityError) || !result.Fails(SeverityWarning) {
+		t.Errorf("wanted result to fail on warnings only")
+	}
+
+	for _, f := range result.Findings() {
+		if f.Type == NameMismatch && f.Severity != SeverityWarning {
+			t.Errorf("wanted name mismatch to be a warning, got: %s", f.Severity)
+		}
+		if f.Type == NonExistingReference && f.Severity != SeverityInfo {
+			t.Errorf("wanted overridden severity info, got: %s", f.Severity)
+		}
+	}
+}
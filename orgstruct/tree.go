@@ -0,0 +1,329 @@
+package orgstruct
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Hierarchies that can be rendered as a tree.
+const (
+	TreeKU  = "ku"
+	TreeFS  = "fs"
+	TreeOEL = "oe-l"
+	TreeOEF = "oe-f"
+)
+
+// treeStyle holds the prefixes used to draw the branches of a tree.
+type treeStyle struct {
+	branch, last, pipe, space string
+}
+
+var (
+	boxStyle   = treeStyle{branch: "├── ", last: "└── ", pipe: "│   ", space: "    "}
+	asciiStyle = treeStyle{branch: "|-- ", last: "`-- ", pipe: "|   ", space: "    "}
+)
+
+// TreeOptions configures RenderTree.
+type TreeOptions struct {
+	// Root is the id of the node to start at. All roots are rendered if it
+	// is empty.
+	Root string
+	// MaxDepth limits the number of levels below the start nodes, 0 means
+	// no limit.
+	MaxDepth int
+	// Errors annotates nodes with the messages of their errors.
+	Errors bool
+	// ASCII draws the branches with ASCII instead of box-drawing characters.
+	ASCII bool
+}
+
+// treeNode is an item of one of the hierarchies prepared for rendering.
+type treeNode struct {
+	id       string
+	name     string
+	label    string
+	errors   []*Error
+	children []*treeNode
+	// cycle marks a node already rendered on the path from the root.
+	cycle bool
+}
+
+func sortTreeNodes(nodes []*treeNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].name != nodes[j].name {
+			return nodes[i].name < nodes[j].name
+		}
+		return nodes[i].id < nodes[j].id
+	})
+}
+
+// The builders below stop at maxDepth and at nodes that are already on the
+// path from the start node, so trees of items in a cycle are finite.
+
+func kuTreeNode(item *KUItem, depth int, maxDepth int, path map[*KUItem]bool) *treeNode {
+	node := &treeNode{id: item.Id, name: item.NameLong, label: fmt.Sprintf("%s (%s)", item.NameLong, item.Id), errors: item.Errors}
+	if path[item] {
+		node.cycle = true
+		return node
+	}
+
+	if maxDepth == 0 || depth < maxDepth {
+		path[item] = true
+		for _, child := range item.Children {
+			node.children = append(node.children, kuTreeNode(child, depth+1, maxDepth, path))
+		}
+		delete(path, item)
+	}
+	sortTreeNodes(node.children)
+	return node
+}
+
+func fsTreeNode(item *FSItem, depth int, maxDepth int, path map[*FSItem]bool) *treeNode {
+	node := &treeNode{id: item.Id, name: item.NameLong, label: fmt.Sprintf("%s (%s)", item.NameLong, item.Id), errors: item.Errors}
+	if path[item] {
+		node.cycle = true
+		return node
+	}
+
+	if maxDepth == 0 || depth < maxDepth {
+		path[item] = true
+		for _, child := range item.Children {
+			node.children = append(node.children, fsTreeNode(child, depth+1, maxDepth, path))
+		}
+		delete(path, item)
+	}
+	sortTreeNodes(node.children)
+	return node
+}
+
+// oeLabel shows OrgKZ, Typ and the first line of the name of an OE.
+func oeLabel(item *OEItem) string {
+	var parts []string
+	if item.OrgKZ != "" {
+		parts = append(parts, item.OrgKZ)
+	}
+	if item.Type != "" {
+		parts = append(parts, "["+item.Type+"]")
+	}
+	if item.OrgName1 != "" {
+		parts = append(parts, item.OrgName1)
+	}
+	return strings.Join(append(parts, "("+item.Id+")"), " ")
+}
+
+func oeLTreeNode(item *OEItem, depth int, maxDepth int, path map[*OEItem]bool) *treeNode {
+	node := &treeNode{id: item.Id, name: item.OrgKZ, label: oeLabel(item), errors: item.Errors}
+	if path[item] {
+		node.cycle = true
+		return node
+	}
+
+	if maxDepth == 0 || depth < maxDepth {
+		path[item] = true
+		for _, child := range item.LChildren {
+			node.children = append(node.children, oeLTreeNode(child, depth+1, maxDepth, path))
+		}
+		delete(path, item)
+	}
+	sortTreeNodes(node.children)
+	return node
+}
+
+func oeFTreeNode(item *OEItem, depth int, maxDepth int, path map[*OEItem]bool) *treeNode {
+	node := &treeNode{id: item.Id, name: item.OrgKZ, label: oeLabel(item), errors: item.Errors}
+	if path[item] {
+		node.cycle = true
+		return node
+	}
+
+	if maxDepth == 0 || depth < maxDepth {
+		path[item] = true
+		for _, child := range item.FChildren {
+			node.children = append(node.children, oeFTreeNode(child, depth+1, maxDepth, path))
+		}
+		delete(path, item)
+	}
+	sortTreeNodes(node.children)
+	return node
+}
+
+// treeRoots returns the start nodes of a hierarchy: the item with the given
+// id or all items without a parent in that hierarchy. Items in a cycle have
+// a parent but cannot be reached from those, so the lowest id of every
+// unreached cycle is added as a start node as well. Items below a cycle are
+// reached from there.
+func treeRoots(hierarchy string, options TreeOptions, oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) ([]*treeNode, error) {
+	var ids []string
+	var parentOf func(id string) string
+	var build func(id string, maxDepth int) *treeNode
+
+	switch hierarchy {
+	case TreeKU:
+		for id := range kuMap {
+			ids = append(ids, id)
+		}
+		parentOf = func(id string) string {
+			if parent := kuMap[id].Parent; parent != nil {
+				return parent.Id
+			}
+			return ""
+		}
+		build = func(id string, maxDepth int) *treeNode {
+			return kuTreeNode(kuMap[id], 0, maxDepth, make(map[*KUItem]bool))
+		}
+	case TreeFS:
+		for id := range fsMap {
+			ids = append(ids, id)
+		}
+		parentOf = func(id string) string {
+			if parent := fsMap[id].Parent; parent != nil {
+				return parent.Id
+			}
+			return ""
+		}
+		build = func(id string, maxDepth int) *treeNode {
+			return fsTreeNode(fsMap[id], 0, maxDepth, make(map[*FSItem]bool))
+		}
+	case TreeOEL:
+		for id := range oeMap {
+			ids = append(ids, id)
+		}
+		parentOf = func(id string) string {
+			if parent := oeMap[id].ParentL; parent != nil {
+				return parent.Id
+			}
+			return ""
+		}
+		build = func(id string, maxDepth int) *treeNode {
+			return oeLTreeNode(oeMap[id], 0, maxDepth, make(map[*OEItem]bool))
+		}
+	case TreeOEF:
+		for id := range oeMap {
+			ids = append(ids, id)
+		}
+		parentOf = func(id string) string {
+			if parent := oeMap[id].ParentF; parent != nil {
+				return parent.Id
+			}
+			return ""
+		}
+		build = func(id string, maxDepth int) *treeNode {
+			return oeFTreeNode(oeMap[id], 0, maxDepth, make(map[*OEItem]bool))
+		}
+	default:
+		return nil, fmt.Errorf("unknown hierarchy: %s", hierarchy)
+	}
+	sort.Strings(ids)
+
+	if options.Root != "" {
+		for _, id := range ids {
+			if id == options.Root {
+				return []*treeNode{build(id, options.MaxDepth)}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown start node: %s", options.Root)
+	}
+
+	var roots []*treeNode
+	reached := make(map[string]bool)
+	add := func(id string) {
+		root := build(id, options.MaxDepth)
+		if options.MaxDepth > 0 {
+			// the limited tree does not cover the deeper levels
+			markTreeNodes(build(id, 0), reached)
+		} else {
+			markTreeNodes(root, reached)
+		}
+		roots = append(roots, root)
+	}
+
+	for _, id := range ids {
+		if parentOf(id) == "" {
+			add(id)
+		}
+	}
+	for _, id := range ids {
+		if !reached[id] {
+			add(lowestInCycle(id, parentOf))
+		}
+	}
+
+	sortTreeNodes(roots)
+	return roots, nil
+}
+
+// lowestInCycle follows the parents from id until an item repeats and
+// returns the lowest id of the cycle found.
+func lowestInCycle(id string, parentOf func(id string) string) string {
+	seen := make(map[string]bool)
+	for !seen[id] {
+		seen[id] = true
+		id = parentOf(id)
+	}
+
+	lowest := id
+	for next := parentOf(id); next != id; next = parentOf(next) {
+		if next < lowest {
+			lowest = next
+		}
+	}
+	return lowest
+}
+
+func markTreeNodes(node *treeNode, reached map[string]bool) {
+	reached[node.id] = true
+	for _, child := range node.children {
+		markTreeNodes(child, reached)
+	}
+}
+
+// RenderTree writes the given hierarchy as an indented tree. buildTrees has
+// to run before, and analyzeTrees as well for error annotations.
+func RenderTree(w io.Writer, hierarchy string, options TreeOptions, oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem) error {
+	roots, err := treeRoots(hierarchy, options, oeMap, kuMap, fsMap)
+	if err != nil {
+		return err
+	}
+
+	style := boxStyle
+	if options.ASCII {
+		style = asciiStyle
+	}
+
+	for _, root := range roots {
+		if err := writeTreeNode(w, root, "", "", style, options.Errors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTreeNode(w io.Writer, node *treeNode, prefix string, childPrefix string, style treeStyle, annotate bool) error {
+	line := prefix + node.label
+	if node.cycle {
+		line += " (cycle)"
+	} else if annotate && len(node.errors) > 0 {
+		messages := make([]string, len(node.errors))
+		for i, e := range node.errors {
+			messages[i] = e.Message
+		}
+		line += " ! " + strings.Join(messages, "; ")
+	}
+
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+
+	for i, child := range node.children {
+		branch, pipe := style.branch, style.pipe
+		if i == len(node.children)-1 {
+			branch, pipe = style.last, style.space
+		}
+		if err := writeTreeNode(w, child, childPrefix+branch, childPrefix+pipe, style, annotate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
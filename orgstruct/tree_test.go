@@ -0,0 +1,96 @@
+package orgstruct
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRenderTreeKU(t *testing.T) {
+	kuMap := map[string]*KUItem{
+		"ku1": {Id: "ku1", NameLong: "Konzern"},
+		"ku2": {Id: "ku2", ParentId: "ku1", NameLong: "Tochter B", Depth: 1},
+		"ku3": {Id: "ku3", ParentId: "ku1", NameLong: "Tochter A", Depth: 1},
+		"ku4": {Id: "ku4", ParentId: "ku2", NameLong: "Enkel", Depth: 1},
+	}
+	oeMap := make(map[string]*OEItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := RenderTree(&buffer, TreeKU, TreeOptions{Errors: true}, oeMap, kuMap, fsMap); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	expected := "Konzern (ku1)\n" +
+		"├── Tochter A (ku3)\n" +
+		"└── Tochter B (ku2)\n" +
+		"    └── Enkel (ku4) ! DEPTH differs from position in tree\n"
+	if buffer.String() != expected {
+		t.Errorf("wanted:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	if err := RenderTree(&buffer, TreeKU, TreeOptions{Root: "ku2", MaxDepth: 1, ASCII: true}, oeMap, kuMap, fsMap); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	expected = "Tochter B (ku2)\n`-- Enkel (ku4)\n"
+	if buffer.String() != expected {
+		t.Errorf("wanted:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+}
+
+func TestRenderTreeOECycle(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", ParentLId: "oe2", OrgKZ: "A", Type: "Stab"},
+		"oe2": {Id: "oe2", ParentLId: "oe1", OrgKZ: "B", Type: "Team"},
+	}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := RenderTree(&buffer, TreeOEL, TreeOptions{Root: "oe1"}, oeMap, kuMap, fsMap); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	expected := "A [Stab] (oe1)\n└── B [Team] (oe2)\n    └── A [Stab] (oe1) (cycle)\n"
+	if buffer.String() != expected {
+		t.Errorf("wanted:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+}
+
+func TestRenderTreeUnknown(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := RenderTree(&buffer, "xy", TreeOptions{}, nil, nil, nil); err == nil {
+		t.Errorf("wanted error for unknown hierarchy, got none")
+	}
+
+	if err := RenderTree(&buffer, TreeFS, TreeOptions{Root: "fs1"}, nil, nil, nil); err == nil {
+		t.Errorf("wanted error for unknown start node, got none")
+	}
+}
+
+func TestRenderTreeUnreachedCycle(t *testing.T) {
+	oeMap := map[string]*OEItem{
+		"oe1": {Id: "oe1", OrgKZ: "A"},
+		"oe2": {Id: "oe2", ParentLId: "oe3", OrgKZ: "B"},
+		"oe3": {Id: "oe3", ParentLId: "oe4", OrgKZ: "C"},
+		"oe4": {Id: "oe4", ParentLId: "oe3", OrgKZ: "D"},
+	}
+	kuMap := make(map[string]*KUItem)
+	fsMap := make(map[string]*FSItem)
+	buildTrees(oeMap, kuMap, fsMap)
+
+	var buffer bytes.Buffer
+	if err := RenderTree(&buffer, TreeOEL, TreeOptions{MaxDepth: 1}, oeMap, kuMap, fsMap); err != nil {
+		t.Fatalf("wanted no error, got: %s", err)
+	}
+
+	// oe2 hangs below the cycle and is only shown there
+	expected := "A (oe1)\nC (oe3)\n├── B (oe2)\n└── D (oe4)\n"
+	if buffer.String() != expected {
+		t.Errorf("wanted:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+}
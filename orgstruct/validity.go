@@ -0,0 +1,58 @@
+package orgstruct
+
+import (
+	"fmt"
+	"time"
+)
+
+// interval is the validity period of an item. Both ends are inclusive and a
+// zero time stands for an open end, as the attributes are optional.
+type interval struct {
+	from  time.Time
+	until time.Time
+}
+
+func (i interval) valid() bool {
+	return i.from.IsZero() || i.until.IsZero() || !i.from.After(i.until)
+}
+
+// contains reports whether other lies completely within i.
+func (i interval) contains(other interval) bool {
+	if !i.from.IsZero() && (other.from.IsZero() || other.from.Before(i.from)) {
+		return false
+	}
+
+	if !i.until.IsZero() && (other.until.IsZero() || other.until.After(i.until)) {
+		return false
+	}
+
+	return true
+}
+
+// includes reports whether i covers the given point in time.
+func (i interval) includes(t time.Time) bool {
+	return (i.from.IsZero() || !t.Before(i.from)) && (i.until.IsZero() || !t.After(i.until))
+}
+
+func (i interval) String() string {
+	return fmt.Sprintf("%s..%s", formatDate(i.from), formatDate(i.until))
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func (item *FSItem) validity() interval {
+	return interval{from: item.From.Time, until: item.Until.Time}
+}
+
+func (item *KUItem) validity() interval {
+	return interval{from: item.From.Time, until: item.Until.Time}
+}
+
+func (item *OEItem) validity() interval {
+	return interval{from: item.From.Time, until: item.Until.Time}
+}
@@ -0,0 +1,52 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestIntervalValid(t *testing.T) {
+	cases := []struct {
+		validity interval
+		valid    bool
+	}{
+		{interval{}, true},
+		{interval{from: parseTime("2020-01-01T00:00:00").Time}, true},
+		{interval{from: parseTime("2020-01-01T00:00:00").Time, until: parseTime("2020-01-01T00:00:00").Time}, true},
+		{interval{from: parseTime("2020-01-02T00:00:00").Time, until: parseTime("2020-01-01T00:00:00").Time}, false},
+	}
+
+	for _, c := range cases {
+		if c.validity.valid() != c.valid {
+			t.Errorf("wanted %v to be valid: %t", c.validity, c.valid)
+		}
+	}
+}
+
+func TestIntervalContains(t *testing.T) {
+	outer := interval{
+		from:  parseTime("2000-01-01T00:00:00").Time,
+		until: parseTime("2020-12-31T00:00:00").Time,
+	}
+
+	cases := []struct {
+		other    interval
+		contains bool
+	}{
+		{outer, true},
+		{interval{from: parseTime("2005-01-01T00:00:00").Time, until: parseTime("2010-12-31T00:00:00").Time}, true},
+		{interval{from: parseTime("1999-12-31T00:00:00").Time, until: parseTime("2010-12-31T00:00:00").Time}, false},
+		{interval{from: parseTime("2005-01-01T00:00:00").Time, until: parseTime("2021-01-01T00:00:00").Time}, false},
+		{interval{from: parseTime("2005-01-01T00:00:00").Time}, false},
+		{interval{}, false},
+	}
+
+	for _, c := range cases {
+		if outer.contains(c.other) != c.contains {
+			t.Errorf("wanted %v to contain %v: %t", outer, c.other, c.contains)
+		}
+	}
+
+	if !(interval{}).contains(outer) {
+		t.Errorf("wanted open interval to contain %v", outer)
+	}
+}
@@ -0,0 +1,225 @@
+package orgstruct
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The exports may contain several versions of the same id with different
+// validity periods. Versions are kept ordered by the start of their validity
+// and, for equal starts, in the order of the file.
+
+func sortFSVersions(fsVersions map[string][]*FSItem) {
+	for _, versions := range fsVersions {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].From.Before(versions[j].From.Time)
+		})
+	}
+}
+
+func sortKUVersions(kuVersions map[string][]*KUItem) {
+	for _, versions := range kuVersions {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].From.Before(versions[j].From.Time)
+		})
+	}
+}
+
+func sortOEVersions(oeVersions map[string][]*OEItem) {
+	for _, versions := range oeVersions {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].From.Before(versions[j].From.Time)
+		})
+	}
+}
+
+// latestVersions picks the latest version of every id to build the trees
+// from.
+func latestVersions(oeVersions map[string][]*OEItem, kuVersions map[string][]*KUItem, fsVersions map[string][]*FSItem) (map[string]*OEItem, map[string]*KUItem, map[string]*FSItem) {
+	oeMap := make(map[string]*OEItem)
+	for k, v := range oeVersions {
+		oeMap[k] = v[len(v)-1]
+	}
+
+	kuMap := make(map[string]*KUItem)
+	for k, v := range kuVersions {
+		kuMap[k] = v[len(v)-1]
+	}
+
+	fsMap := make(map[string]*FSItem)
+	for k, v := range fsVersions {
+		fsMap[k] = v[len(v)-1]
+	}
+
+	return oeMap, kuMap, fsMap
+}
+
+// versionsValidAt picks the version of every id that is valid at the given
+// point in time, dropping ids without such a version. It has to run before
+// buildTrees, so that references to items which are not valid at that time
+// are reported as non-existing. If several versions are valid, the latest
+// one wins.
+func versionsValidAt(at time.Time, oeVersions map[string][]*OEItem, kuVersions map[string][]*KUItem, fsVersions map[string][]*FSItem) (map[string]*OEItem, map[string]*KUItem, map[string]*FSItem) {
+	oeMap := make(map[string]*OEItem)
+	for k, versions := range oeVersions {
+		for _, v := range versions {
+			if v.validity().includes(at) {
+				oeMap[k] = v
+			}
+		}
+	}
+
+	kuMap := make(map[string]*KUItem)
+	for k, versions := range kuVersions {
+		for _, v := range versions {
+			if v.validity().includes(at) {
+				kuMap[k] = v
+			}
+		}
+	}
+
+	fsMap := make(map[string]*FSItem)
+	for k, versions := range fsVersions {
+		for _, v := range versions {
+			if v.validity().includes(at) {
+				fsMap[k] = v
+			}
+		}
+	}
+
+	return oeMap, kuMap, fsMap
+}
+
+const (
+	VERSION_OVERLAP = "validity overlaps previous version"
+	VERSION_GAP     = "validity gap after previous version"
+)
+
+func addVersionOverlapError(item *ItemWithError, message string, detail string) *Error {
+	e := &Error{Message: message, Type: VersionOverlap, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+func addVersionGapError(item *ItemWithError, message string, detail string) *Error {
+	e := &Error{Message: message, Type: VersionGap, Detail: detail}
+	item.Errors = append(item.Errors, e)
+	return e
+}
+
+// compareVersions checks whether next, the validity of the version following
+// previous, overlaps it or leaves a gap after it. Validity is measured in
+// days, so a version ending on one day and the next one starting on the
+// following day are contiguous.
+func compareVersions(previous interval, next interval) (overlap bool, gap bool) {
+	if previous.until.IsZero() || !next.from.After(previous.until) {
+		return true, false
+	}
+
+	return false, next.from.After(previous.until.AddDate(0, 0, 1))
+}
+
+// endsLater reports whether a ends after b. A zero until never ends.
+func endsLater(a interval, b interval) bool {
+	if b.until.IsZero() {
+		return false
+	}
+	return a.until.IsZero() || a.until.After(b.until)
+}
+
+// selectedVersions keeps the versions of the ids that are in the given maps,
+// so that a run limited by versionsValidAt only checks the history of the
+// items it analyzes.
+func selectedVersions(oeMap map[string]*OEItem, kuMap map[string]*KUItem, fsMap map[string]*FSItem, oeVersions map[string][]*OEItem, kuVersions map[string][]*KUItem, fsVersions map[string][]*FSItem) (map[string][]*OEItem, map[string][]*KUItem, map[string][]*FSItem) {
+	oeSelected := make(map[string][]*OEItem)
+	for id := range oeMap {
+		oeSelected[id] = oeVersions[id]
+	}
+
+	kuSelected := make(map[string][]*KUItem)
+	for id := range kuMap {
+		kuSelected[id] = kuVersions[id]
+	}
+
+	fsSelected := make(map[string][]*FSItem)
+	for id := range fsMap {
+		fsSelected[id] = fsVersions[id]
+	}
+
+	return oeSelected, kuSelected, fsSelected
+}
+
+// analyzeVersions compares each version of an id with the earlier version
+// ending last and reports overlapping or gapped validity periods on the later
+// one. A version lying within a longer one thus does not cause a gap. Versions with
+// invalid validity periods are reported by analyzeTrees and skipped here.
+func analyzeVersions(oeVersions map[string][]*OEItem, kuVersions map[string][]*KUItem, fsVersions map[string][]*FSItem, result *Errors) {
+	for _, versions := range kuVersions {
+		var previous *KUItem
+		for _, v := range versions {
+			if !v.validity().valid() {
+				continue
+			}
+
+			if previous != nil {
+				detail := fmt.Sprintf("%s after %s", v.validity(), previous.validity())
+				overlap, gap := compareVersions(previous.validity(), v.validity())
+				if overlap {
+					addKUError(v, addVersionOverlapError(&v.ItemWithError, VERSION_OVERLAP, detail), result)
+				} else if gap {
+					addKUError(v, addVersionGapError(&v.ItemWithError, VERSION_GAP, detail), result)
+				}
+			}
+			if previous == nil || endsLater(v.validity(), previous.validity()) {
+				previous = v
+			}
+		}
+	}
+
+	for _, versions := range fsVersions {
+		var previous *FSItem
+		for _, v := range versions {
+			if !v.validity().valid() {
+				continue
+			}
+
+			if previous != nil {
+				detail := fmt.Sprintf("%s after %s", v.validity(), previous.validity())
+				overlap, gap := compareVersions(previous.validity(), v.validity())
+				if overlap {
+					addFSError(v, addVersionOverlapError(&v.ItemWithError, VERSION_OVERLAP, detail), result)
+				} else if gap {
+					addFSError(v, addVersionGapError(&v.ItemWithError, VERSION_GAP, detail), result)
+				}
+			}
+			if previous == nil || endsLater(v.validity(), previous.validity()) {
+				previous = v
+			}
+		}
+	}
+
+	for _, versions := range oeVersions {
+		var previous *OEItem
+		for _, v := range versions {
+			if !v.validity().valid() {
+				continue
+			}
+
+			if previous != nil {
+				detail := fmt.Sprintf("%s after %s", v.validity(), previous.validity())
+				overlap, gap := compareVersions(previous.validity(), v.validity())
+				if overlap {
+					addOEError(v, addVersionOverlapError(&v.ItemWithError, VERSION_OVERLAP, detail), result)
+				} else if gap {
+					addOEError(v, addVersionGapError(&v.ItemWithError, VERSION_GAP, detail), result)
+				}
+			}
+			if previous == nil || endsLater(v.validity(), previous.validity()) {
+				previous = v
+			}
+		}
+	}
+
+	result.sort()
+}
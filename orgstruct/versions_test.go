@@ -0,0 +1,174 @@
+package orgstruct
+
+import (
+	"testing"
+)
+
+func TestParseKUVersions(t *testing.T) {
+	input := `<vw_KU>
+				<KU s_NODE_KU_ID="ku1" GAB="2010-01-01T00:00:00" GBIS="9999-12-31T00:00:00" KULANG="new" />
+				<KU s_NODE_KU_ID="ku1" GAB="1900-01-01T00:00:00" GBIS="2009-12-31T00:00:00" KULANG="old" />
+				<KU s_NODE_KU_ID="ku2" GAB="1900-01-01T00:00:00" GBIS="9999-12-31T00:00:00" KULANG="other" />
+			  </vw_KU>`
+
+	kuVersions, _, err := parseKUBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("wanted no parsing error, got: %s", err)
+	}
+
+	if len(kuVersions) != 2 {
+		t.Errorf("wanted 2 ids, got: %d", len(kuVersions))
+	}
+
+	versions := kuVersions["ku1"]
+	if len(versions) != 2 || versions[0].NameLong != "old" || versions[1].NameLong != "new" {
+		t.Fatalf("wanted versions old and new of ku1, got: %d versions", len(versions))
+	}
+
+	_, kuMap, _ := latestVersions(map[string][]*OEItem{}, kuVersions, map[string][]*FSItem{})
+	if kuMap["ku1"].NameLong != "new" || kuMap["ku2"].NameLong != "other" {
+		t.Errorf("wanted latest versions, got: %s and %s", kuMap["ku1"].NameLong, kuMap["ku2"].NameLong)
+	}
+}
+
+func TestVersionsValidAt(t *testing.T) {
+	kuVersions := map[string][]*KUItem{
+		"ku1": {{Id: "ku1"}},
+		"ku2": {{Id: "ku2", Until: parseTime("2019-12-31T00:00:00")}},
+	}
+	fsVersions := map[string][]*FSItem{
+		"fs1": {
+			{Id: "fs1", NameLong: "old", Until: parseTime("2019-12-31T00:00:00")},
+			{Id: "fs1", NameLong: "new", From: parseTime("2020-01-01T00:00:00")},
+		},
+		"fs2": {{Id: "fs2", From: parseTime("2020-01-02T00:00:00")}},
+	}
+	oeVersions := map[string][]*OEItem{
+		"oe1": {{Id: "oe1", KUId: "ku2", From: parseTime("2010-01-01T00:00:00"), Until: parseTime("2020-01-01T00:00:00")}},
+	}
+
+	at := parseTime("2020-01-01T00:00:00").Time
+	oeMap, kuMap, fsMap := versionsValidAt(at, oeVersions, kuVersions, fsVersions)
+
+	if len(kuMap) != 1 || kuMap["ku1"] == nil {
+		t.Errorf("wanted only ku1, got: %d items", len(kuMap))
+	}
+
+	if len(fsMap) != 1 || fsMap["fs1"] == nil || fsMap["fs1"].NameLong != "new" {
+		t.Errorf("wanted only the new version of fs1, got: %d items", len(fsMap))
+	}
+
+	if len(oeMap) != 1 || oeMap["oe1"] == nil {
+		t.Errorf("wanted only oe1, got: %d items", len(oeMap))
+	}
+
+	buildTrees(oeMap, kuMap, fsMap)
+	analyzeTrees(oeMap, kuMap, fsMap)
+
+	assertError(Error{Message: NON_EXISTING_RELATED_KU, Type: NonExistingReference}, oeMap["oe1"].Errors, t)
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		previous interval
+		next     interval
+		overlap  bool
+		gap      bool
+	}{
+		{
+			interval{until: parseTime("2019-12-31T00:00:00").Time},
+			interval{from: parseTime("2020-01-01T00:00:00").Time},
+			false, false,
+		},
+		{
+			interval{until: parseTime("2019-12-31T00:00:00").Time},
+			interval{from: parseTime("2019-12-31T00:00:00").Time},
+			true, false,
+		},
+		{
+			interval{until: parseTime("2019-12-31T00:00:00").Time},
+			interval{from: parseTime("2020-01-02T00:00:00").Time},
+			false, true,
+		},
+		{
+			interval{from: parseTime("2010-01-01T00:00:00").Time},
+			interval{from: parseTime("2020-01-01T00:00:00").Time},
+			true, false,
+		},
+	}
+
+	for _, c := range cases {
+		overlap, gap := compareVersions(c.previous, c.next)
+		if overlap != c.overlap || gap != c.gap {
+			t.Errorf("wanted %s after %s to overlap: %t, gap: %t", c.next, c.previous, c.overlap, c.gap)
+		}
+	}
+}
+
+func TestAnalyzeVersions(t *testing.T) {
+	oe1 := &OEItem{Id: "oe1", Until: parseTime("2009-12-31T00:00:00")}
+	oe2 := &OEItem{Id: "oe1", From: parseTime("2010-01-01T00:00:00"), Until: parseTime("2014-12-31T00:00:00")}
+	oe3 := &OEItem{Id: "oe1", From: parseTime("2016-01-01T00:00:00")}
+	ku1 := &KUItem{Id: "ku1", Until: parseTime("2015-12-31T00:00:00")}
+	ku2 := &KUItem{Id: "ku1", From: parseTime("2010-01-01T00:00:00")}
+
+	oeVersions := map[string][]*OEItem{"oe1": {oe1, oe2, oe3}}
+	kuVersions := map[string][]*KUItem{"ku1": {ku1, ku2}}
+	fsVersions := map[string][]*FSItem{}
+
+	result := &Errors{}
+	analyzeVersions(oeVersions, kuVersions, fsVersions, result)
+
+	assertErrorCount(0, oe1.Errors, t)
+	assertErrorCount(0, oe2.Errors, t)
+	assertErrorCount(1, oe3.Errors, t)
+	assertError(Error{Message: VERSION_GAP, Type: VersionGap}, oe3.Errors, t)
+	assertErrorCount(0, ku1.Errors, t)
+	assertErrorCount(1, ku2.Errors, t)
+	assertError(Error{Message: VERSION_OVERLAP, Type: VersionOverlap}, ku2.Errors, t)
+
+	if result.Count() != 2 {
+		t.Errorf("wanted 2 errors in report, got: %d", result.Count())
+	}
+
+	if oe3.Errors[0].Detail != "2016-01-01.. after 2010-01-01..2014-12-31" {
+		t.Errorf("unexpected detail: %s", oe3.Errors[0].Detail)
+	}
+}
+
+func TestAnalyzeSelectedVersions(t *testing.T) {
+	oe1 := &OEItem{Id: "oe1", Until: parseTime("2009-12-31T00:00:00")}
+	oe2 := &OEItem{Id: "oe1", From: parseTime("2016-01-01T00:00:00")}
+	oe3 := &OEItem{Id: "oe2", Until: parseTime("2009-12-31T00:00:00")}
+	oe4 := &OEItem{Id: "oe2", From: parseTime("2016-01-01T00:00:00")}
+
+	oeVersions := map[string][]*OEItem{"oe1": {oe1, oe2}, "oe2": {oe3, oe4}}
+	oeMap := map[string]*OEItem{"oe1": oe1}
+
+	result := &Errors{}
+	oeSelected, kuSelected, fsSelected := selectedVersions(oeMap, nil, nil, oeVersions, nil, nil)
+	analyzeVersions(oeSelected, kuSelected, fsSelected, result)
+
+	assertErrorCount(1, oe2.Errors, t)
+	assertErrorCount(0, oe4.Errors, t)
+	if result.Count() != 1 {
+		t.Errorf("wanted only the gap of the selected oe1, got: %d errors", result.Count())
+	}
+}
+
+func TestAnalyzeVersionsContained(t *testing.T) {
+	oe1 := &OEItem{Id: "oe1", From: parseTime("2000-01-01T00:00:00"), Until: parseTime("2015-12-31T00:00:00")}
+	oe2 := &OEItem{Id: "oe1", From: parseTime("2005-01-01T00:00:00"), Until: parseTime("2005-12-31T00:00:00")}
+	oe3 := &OEItem{Id: "oe1", From: parseTime("2010-01-01T00:00:00"), Until: parseTime("2020-12-31T00:00:00")}
+
+	result := &Errors{}
+	analyzeVersions(map[string][]*OEItem{"oe1": {oe1, oe2, oe3}}, nil, nil, result)
+
+	assertErrorCount(1, oe2.Errors, t)
+	assertError(Error{Message: VERSION_OVERLAP, Type: VersionOverlap}, oe2.Errors, t)
+	assertErrorCount(1, oe3.Errors, t)
+	assertError(Error{Message: VERSION_OVERLAP, Type: VersionOverlap}, oe3.Errors, t)
+	if oe3.Errors[0].Detail != "2010-01-01..2020-12-31 after 2000-01-01..2015-12-31" {
+		t.Errorf("wanted oe3 compared with oe1, got: %s", oe3.Errors[0].Detail)
+	}
+}